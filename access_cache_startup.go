@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/konflux-ci/namespace-lister/internal/log"
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
@@ -15,9 +17,33 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// buildAndStartSynchronizedAccessCache builds a SynchronizedAccessCache.
+// synchRetryPeriod is the period the first synchronization is retried with
+// while another one is running
+const synchRetryPeriod = 100 * time.Millisecond
+
+// buildSynchronizedAccessCaches builds a SynchronizedAccessCache for each access profile.
+// Metrics of each cache are labeled with the name of the profile.
+func buildSynchronizedAccessCaches(ctx context.Context, resourceCache crcache.Cache, registry prometheus.Registerer) (synchronizedAccessCaches, error) {
+	pp, err := resourcecache.GetAccessProfilesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cc := make(synchronizedAccessCaches, len(pp))
+	for _, p := range pp {
+		reg := prometheus.WrapRegistererWith(prometheus.Labels{"profile": p.Name}, registry)
+		c, err := buildSynchronizedAccessCache(ctx, resourceCache, reg, p)
+		if err != nil {
+			return nil, err
+		}
+		cc[p.Name] = c
+	}
+	return cc, nil
+}
+
+// buildSynchronizedAccessCache builds a SynchronizedAccessCache for the access profile.
 // It registers handlers on events on resources that will trigger an AccessCache synchronization.
-func buildAndStartSynchronizedAccessCache(ctx context.Context, resourceCache crcache.Cache, registry prometheus.Registerer) (*cache.SynchronizedAccessCache, error) {
+func buildSynchronizedAccessCache(ctx context.Context, resourceCache crcache.Cache, registry prometheus.Registerer, profile resourcecache.AccessProfile) (*cache.SynchronizedAccessCache, error) {
 	acm, err := resourcecache.BuildAndRegisterAccessCacheMetrics(registry)
 	if err != nil {
 		return nil, err
@@ -28,9 +54,14 @@ func buildAndStartSynchronizedAccessCache(ctx context.Context, resourceCache crc
 	synchCache := cache.NewSynchronizedAccessCache(
 		sae,
 		resourceCache, cache.CacheSynchronizerOptions{
-			Logger:       log.GetLoggerFromContext(ctx),
+			Logger:       log.GetLoggerFromContext(ctx).With("profile", profile.Name),
 			ResyncPeriod: resourcecache.GetValidResyncPeriodFromEnvOrZero(ctx),
+			Concurrency:  resourcecache.GetValidConcurrencyFromEnvOrZero(ctx),
 			Metrics:      acm,
+
+			SnapshotStore:    resourcecache.GetSnapshotStoreFromEnvOrNil(profile.Name),
+			SnapshotMaxAge:   resourcecache.GetValidSnapshotMaxAgeFromEnvOrZero(ctx),
+			AccessAttributes: profile.AccessAttributes,
 		},
 	)
 
@@ -52,11 +83,100 @@ func buildAndStartSynchronizedAccessCache(ctx context.Context, resourceCache crc
 			return nil, err
 		}
 	}
+	return synchCache, nil
+}
+
+// startSynchronizedAccessCache starts the SynchronizedAccessCache and
+// waits for its first synchronization to complete.
+// The resource cache is expected to be synced.
+//
+// Event handlers are registered before the resource cache starts, so the background
+// synchronization may already be running. In that case, it waits for the cache to be
+// restocked with fresh data, retrying the synchronization if the running one does not provide them.
+func startSynchronizedAccessCache(ctx context.Context, synchCache *cache.SynchronizedAccessCache) error {
 	synchCache.Start(ctx)
 
-	if err := synchCache.Synch(ctx); err != nil {
-		return nil, err
+	for {
+		restocked := synchCache.Restocked()
+		err := synchCache.Synch(ctx)
+		if !errors.Is(err, cache.ErrSynchAlreadyRunning) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-restocked:
+			// only full synchronizations replace data restored from a snapshot
+			if !synchCache.Stale() {
+				return nil
+			}
+		case <-time.After(synchRetryPeriod):
+			// the running synchronization may have failed
+		}
 	}
-	return synchCache, nil
 }
 
+// synchronizedAccessCaches maps the name of each access profile to its SynchronizedAccessCache
+type synchronizedAccessCaches map[string]*cache.SynchronizedAccessCache
+
+// LoadSnapshot restores each cache from its last snapshot.
+// It returns true only if all the caches have been restored.
+func (cc synchronizedAccessCaches) LoadSnapshot() (bool, error) {
+	warm := true
+	for _, c := range cc {
+		ok, err := c.LoadSnapshot()
+		if err != nil {
+			return false, err
+		}
+		warm = warm && ok
+	}
+	return warm, nil
+}
+
+// LastSynchTime returns the time of the oldest last successful synchronization among the caches,
+// or the zero time if any cache has never been synchronized
+func (cc synchronizedAccessCaches) LastSynchTime() time.Time {
+	return cc.oldest((*cache.SynchronizedAccessCache).LastSynchTime)
+}
+
+// DataTime returns the time the oldest data among the caches has been calculated at,
+// or the zero time if any cache has no data
+func (cc synchronizedAccessCaches) DataTime() time.Time {
+	return cc.oldest((*cache.SynchronizedAccessCache).DataTime)
+}
+
+// oldest returns the oldest among the times returned for each cache,
+// or the zero time if it is returned for any cache
+func (cc synchronizedAccessCaches) oldest(timeOf func(*cache.SynchronizedAccessCache) time.Time) time.Time {
+	var t time.Time
+	for _, c := range cc {
+		ct := timeOf(c)
+		if ct.IsZero() {
+			return ct
+		}
+		if t.IsZero() || ct.Before(t) {
+			t = ct
+		}
+	}
+	return t
+}
+
+// NamespaceListers builds a NamespaceLister for each cache
+func (cc synchronizedAccessCaches) NamespaceListers() map[string]NamespaceLister {
+	ll := make(map[string]NamespaceLister, len(cc))
+	for p, c := range cc {
+		ll[p] = NewSubjectNamespaceLister(c)
+	}
+	return ll
+}
+
+// startSynchronizedAccessCaches starts all the caches and waits for their first synchronization to complete
+func startSynchronizedAccessCaches(ctx context.Context, cc synchronizedAccessCaches) error {
+	for _, c := range cc {
+		if err := startSynchronizedAccessCache(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
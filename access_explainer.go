@@ -0,0 +1,322 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxExplanationCandidates limits the number of closest non-matching bindings returned
+const maxExplanationCandidates int = 10
+
+// AccessExplanation explains why a subject can or can not access a namespace
+type AccessExplanation struct {
+	// Subject is the subject whose access is explained
+	Subject rbacv1.Subject `json:"subject"`
+	// Groups are the groups of the subject evaluated together with the subject,
+	// the implicit ones included
+	Groups []string `json:"groups,omitempty"`
+	// Namespace is the namespace whose access is explained
+	Namespace string `json:"namespace"`
+	// Access is the action the subject needs to be allowed to perform in the namespace,
+	// in the `verb resource.group` form
+	Access string `json:"access"`
+	// Allowed is true if any binding grants the access to the subject
+	Allowed bool `json:"allowed"`
+	// Grants are the bindings granting the access to the subject
+	Grants []BindingExplanation `json:"grants,omitempty"`
+	// Candidates are the closest bindings not granting the access to the subject.
+	// They are only returned if the access is not allowed.
+	Candidates []BindingExplanation `json:"candidates,omitempty"`
+}
+
+// BindingExplanation describes a RoleBinding or ClusterRoleBinding relevant to the access
+type BindingExplanation struct {
+	// Kind is either RoleBinding or ClusterRoleBinding
+	Kind string `json:"kind"`
+	// Namespace is the namespace of the RoleBinding, empty for ClusterRoleBindings
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the binding
+	Name string `json:"name"`
+	// Group is the group of the subject bound by the binding,
+	// empty if the binding binds the subject itself
+	Group string `json:"group,omitempty"`
+	// RoleRef is the Role or ClusterRole referenced by the binding
+	RoleRef rbacv1.RoleRef `json:"roleRef"`
+	// Rules are the rules of the referenced role granting the access,
+	// or all of its rules if none grants it
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+	// Reason explains why the binding does not grant the access, empty for grants
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccessExplainer explains the access of subjects to namespaces from
+// the RBAC resources cached by the resource cache.
+// As cached roles are trimmed to the rules granting the access of the profiles,
+// and dropped if none does, the roles not granting the access are read live.
+type AccessExplainer struct {
+	retriever *CRAuthRetriever
+	live      client.Reader
+	profiles  map[string]cache.AccessAttributes
+}
+
+// NewAccessExplainer builds an AccessExplainer evaluating the access
+// of the provided access profiles on top of Controller-Runtime's Readers:
+// `cli` is expected to read from the resource cache, while `live` to read
+// the Roles and ClusterRoles from the Kubernetes APIServer.
+func NewAccessExplainer(cli client.Reader, live client.Reader, profiles ...resourcecache.AccessProfile) *AccessExplainer {
+	pp := make(map[string]cache.AccessAttributes, len(profiles))
+	for _, p := range profiles {
+		pp[p.Name] = p.AccessAttributes
+	}
+	return &AccessExplainer{
+		retriever: NewCRAuthRetriever(cli),
+		live:      live,
+		profiles:  pp,
+	}
+}
+
+// binding is the common view of RoleBindings and ClusterRoleBindings
+type binding struct {
+	BindingExplanation
+	subjects []rbacv1.Subject
+}
+
+// Explain returns the bindings granting the subject the access of the given profile to the namespace.
+// Bindings of the provided groups of the subject are evaluated too, as well as the ones of the groups
+// Kubernetes implicitly adds to authenticated users and service accounts.
+// If the access is not granted, the closest non-matching bindings are returned, in order:
+//   - bindings of the subject in scope whose role does not grant the access;
+//   - bindings of the subject granting the access in other namespaces;
+//   - bindings in scope granting the access to other subjects.
+//
+// An empty profile selects the default one.
+func (e *AccessExplainer) Explain(ctx context.Context, profile string, subject rbacv1.Subject, groups []string, namespace string) (*AccessExplanation, error) {
+	if profile == "" {
+		profile = resourcecache.DefaultAccessProfileName
+	}
+	aa, ok := e.profiles[profile]
+	if !ok {
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("unknown access profile %q", profile))
+	}
+
+	bb, err := e.bindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups = subjectGroups(subject, groups)
+	live := &liveRoles{reader: e.live, namespace: namespace}
+	ex := &AccessExplanation{Subject: subject, Groups: groups, Namespace: namespace, Access: aa.String()}
+	var unmatchedRole, otherNamespace, otherSubject []BindingExplanation
+	for _, b := range bb {
+		bound := bindsSubject(b.subjects, b.Namespace, subject)
+		if !bound {
+			if i := slices.IndexFunc(groups, func(g string) bool {
+				return bindsSubject(b.subjects, b.Namespace, rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: g})
+			}); i != -1 {
+				bound, b.Group = true, groups[i]
+			}
+		}
+		inScope := b.Kind == "ClusterRoleBinding" || b.Namespace == namespace
+		if !bound && !inScope {
+			continue
+		}
+
+		// outside the scope, rules are evaluated in the binding's namespace
+		ns := namespace
+		if !inScope {
+			ns = b.Namespace
+		}
+		rules, err := e.roleRules(ctx, b.Namespace, b.RoleRef)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		granting := grantingRules(aa, ns, rules)
+		switch {
+		case bound && inScope && len(granting) > 0:
+			b.Rules = granting
+			ex.Grants = append(ex.Grants, b.BindingExplanation)
+		case bound && inScope:
+			// the cached role is either trimmed or missing, so read all of its rules live
+			rules, found, err := live.rules(ctx, b.RoleRef)
+			switch {
+			case err != nil:
+				return nil, err
+			case !found:
+				b.Reason = fmt.Sprintf("%s %q not found", b.RoleRef.Kind, b.RoleRef.Name)
+			default:
+				b.Rules = rules
+				b.Reason = fmt.Sprintf("%s %q does not grant %s", b.RoleRef.Kind, b.RoleRef.Name, aa)
+			}
+			unmatchedRole = append(unmatchedRole, b.BindingExplanation)
+		case bound && len(granting) > 0:
+			b.Rules = granting
+			b.Reason = fmt.Sprintf("bound in namespace %q", b.Namespace)
+			otherNamespace = append(otherNamespace, b.BindingExplanation)
+		case inScope && len(granting) > 0:
+			b.Rules = granting
+			b.Reason = "subject is not bound"
+			otherSubject = append(otherSubject, b.BindingExplanation)
+		}
+	}
+
+	ex.Allowed = len(ex.Grants) > 0
+	if !ex.Allowed {
+		ex.Candidates = slices.Concat(unmatchedRole, otherNamespace, otherSubject)
+		ex.Candidates = ex.Candidates[:min(len(ex.Candidates), maxExplanationCandidates)]
+	}
+	return ex, nil
+}
+
+// bindings retrieves all the ClusterRoleBindings and RoleBindings, sorted by kind, namespace, and name
+func (e *AccessExplainer) bindings(ctx context.Context) ([]binding, error) {
+	crbb, err := e.retriever.ListClusterRoleBindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rbb, err := e.retriever.ListRoleBindings(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	bb := make([]binding, 0, len(crbb)+len(rbb))
+	for _, crb := range crbb {
+		bb = append(bb, binding{
+			BindingExplanation: BindingExplanation{Kind: "ClusterRoleBinding", Name: crb.Name, RoleRef: crb.RoleRef},
+			subjects:           crb.Subjects,
+		})
+	}
+	for _, rb := range rbb {
+		bb = append(bb, binding{
+			BindingExplanation: BindingExplanation{Kind: "RoleBinding", Namespace: rb.Namespace, Name: rb.Name, RoleRef: rb.RoleRef},
+			subjects:           rb.Subjects,
+		})
+	}
+	slices.SortFunc(bb, func(a, b binding) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	return bb, nil
+}
+
+// roleRules retrieves from the resource cache the rules of the Role or ClusterRole
+// referenced by a binding in the given namespace
+func (e *AccessExplainer) roleRules(ctx context.Context, namespace string, ref rbacv1.RoleRef) ([]rbacv1.PolicyRule, error) {
+	if ref.Kind == "Role" {
+		r, err := e.retriever.GetRole(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		return r.Rules, nil
+	}
+
+	cr, err := e.retriever.GetClusterRole(ctx, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	return cr.Rules, nil
+}
+
+// liveRoles reads live the Roles of a namespace and the ClusterRoles.
+// Each kind is listed once, the first time a role of that kind is looked up,
+// so explaining an access costs at most two requests to the Kubernetes APIServer.
+type liveRoles struct {
+	reader    client.Reader
+	namespace string
+
+	roles        map[string][]rbacv1.PolicyRule
+	clusterRoles map[string][]rbacv1.PolicyRule
+}
+
+// rules retrieves the rules of the Role in the namespace or of the ClusterRole referenced by a binding.
+// It returns false if the role does not exist.
+func (l *liveRoles) rules(ctx context.Context, ref rbacv1.RoleRef) ([]rbacv1.PolicyRule, bool, error) {
+	if ref.Kind == "Role" {
+		if l.roles == nil {
+			rr := rbacv1.RoleList{}
+			if err := l.reader.List(ctx, &rr, client.InNamespace(l.namespace)); err != nil {
+				return nil, false, err
+			}
+			l.roles = make(map[string][]rbacv1.PolicyRule, len(rr.Items))
+			for _, r := range rr.Items {
+				l.roles[r.Name] = r.Rules
+			}
+		}
+		rules, ok := l.roles[ref.Name]
+		return rules, ok, nil
+	}
+
+	if l.clusterRoles == nil {
+		crr := rbacv1.ClusterRoleList{}
+		if err := l.reader.List(ctx, &crr); err != nil {
+			return nil, false, err
+		}
+		l.clusterRoles = make(map[string][]rbacv1.PolicyRule, len(crr.Items))
+		for _, cr := range crr.Items {
+			l.clusterRoles[cr.Name] = cr.Rules
+		}
+	}
+	rules, ok := l.clusterRoles[ref.Name]
+	return rules, ok, nil
+}
+
+// subjectGroups returns the provided groups of the subject together with the ones
+// Kubernetes implicitly adds to authenticated users and service accounts, sorted and without duplicates.
+// Groups have no groups, so none is returned for them.
+func subjectGroups(subject rbacv1.Subject, groups []string) []string {
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		groups = append(slices.Clone(groups), user.AllAuthenticated)
+	case rbacv1.ServiceAccountKind:
+		groups = append(slices.Clone(groups), user.AllAuthenticated)
+		groups = append(groups, serviceaccount.MakeGroupNames(subject.Namespace)...)
+	default:
+		return nil
+	}
+	slices.Sort(groups)
+	return slices.Compact(groups)
+}
+
+// grantingRules returns the rules allowing the action of the access attributes in the namespace
+func grantingRules(aa cache.AccessAttributes, namespace string, rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	ar := aa.AttributesRecordFor(namespace)
+	gg := []rbacv1.PolicyRule{}
+	for i := range rules {
+		if rbac.RuleAllows(&ar, &rules[i]) {
+			gg = append(gg, rules[i])
+		}
+	}
+	return gg
+}
+
+// bindsSubject returns true if any of the subjects of a binding in the given namespace matches the provided subject.
+// As the Kubernetes RBAC does, ServiceAccounts are matched by their username too,
+// and ServiceAccounts without namespace are considered in the binding's namespace.
+func bindsSubject(subjects []rbacv1.Subject, namespace string, subject rbacv1.Subject) bool {
+	return slices.ContainsFunc(subjects, func(s rbacv1.Subject) bool {
+		switch s.Kind {
+		case rbacv1.UserKind:
+			return (subject.Kind == rbacv1.UserKind && subject.Name == s.Name) ||
+				(subject.Kind == rbacv1.ServiceAccountKind && serviceaccount.MakeUsername(subject.Namespace, subject.Name) == s.Name)
+		case rbacv1.GroupKind:
+			return subject.Kind == rbacv1.GroupKind && subject.Name == s.Name
+		case rbacv1.ServiceAccountKind:
+			ns := cmp.Or(s.Namespace, namespace)
+			return (subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == ns && subject.Name == s.Name) ||
+				(subject.Kind == rbacv1.UserKind && serviceaccount.MakeUsername(ns, s.Name) == subject.Name)
+		default:
+			return false
+		}
+	})
+}
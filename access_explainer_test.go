@@ -0,0 +1,237 @@
+package main_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("AccessExplainer", func() {
+	var (
+		user        = rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "myuser"}
+		sa          = rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "myns", Name: "mysa"}
+		getNsRule   = rbacv1.PolicyRule{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"namespaces"}}
+		listPodRule = rbacv1.PolicyRule{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"pods"}}
+
+		getNsClusterRole = &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "ns-get"},
+			Rules:      []rbacv1.PolicyRule{getNsRule, listPodRule},
+		}
+		listPodClusterRole = &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-list"},
+			Rules:      []rbacv1.PolicyRule{listPodRule},
+		}
+	)
+
+	roleBinding := func(namespace, name, role string, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+			Subjects:   subjects,
+		}
+	}
+
+	explainWithGroups := func(ctx context.Context, subject rbacv1.Subject, groups []string, oo ...client.Object) *namespacelister.AccessExplanation {
+		GinkgoHelper()
+
+		cli := fake.NewClientBuilder().WithObjects(oo...).Build()
+		e := namespacelister.NewAccessExplainer(cli, cli, resourcecache.AccessProfile{
+			Name:             resourcecache.DefaultAccessProfileName,
+			AccessAttributes: cache.DefaultAccessAttributes,
+		})
+		ex, err := e.Explain(ctx, "", subject, groups, "myns")
+		Expect(err).NotTo(HaveOccurred())
+		return ex
+	}
+
+	explain := func(ctx context.Context, subject rbacv1.Subject, oo ...client.Object) *namespacelister.AccessExplanation {
+		GinkgoHelper()
+		return explainWithGroups(ctx, subject, nil, oo...)
+	}
+
+	It("returns the bindings granting access", func(ctx context.Context) {
+		// when
+		ex := explain(ctx, user,
+			getNsClusterRole,
+			roleBinding("myns", "ns-get", "ns-get", user),
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "ns-get"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "ns-get"},
+				Subjects:   []rbacv1.Subject{user},
+			},
+			roleBinding("otherns", "ns-get", "ns-get", user),
+		)
+
+		// then
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Access).To(Equal("get namespaces"))
+		Expect(ex.Grants).To(HaveExactElements(
+			And(HaveField("Kind", "ClusterRoleBinding"), HaveField("Name", "ns-get"), HaveField("Rules", ConsistOf(getNsRule))),
+			And(HaveField("Kind", "RoleBinding"), HaveField("Namespace", "myns"), HaveField("Rules", ConsistOf(getNsRule))),
+		))
+		Expect(ex.Candidates).To(BeEmpty())
+	})
+
+	It("matches service accounts bound without namespace or by username", func(ctx context.Context) {
+		// when
+		ex := explain(ctx, sa,
+			getNsClusterRole,
+			roleBinding("myns", "by-sa", "ns-get", rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "mysa"}),
+			roleBinding("myns", "by-username", "ns-get", rbacv1.Subject{Kind: rbacv1.UserKind, Name: "system:serviceaccount:myns:mysa"}),
+		)
+
+		// then
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Grants).To(HaveExactElements(HaveField("Name", "by-sa"), HaveField("Name", "by-username")))
+	})
+
+	It("returns the bindings granting access to the groups of the subject", func(ctx context.Context) {
+		// given
+		group := func(name string) rbacv1.Subject {
+			return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+		}
+
+		// when
+		ex := explainWithGroups(ctx, user, []string{"mygroup"},
+			getNsClusterRole,
+			roleBinding("myns", "by-group", "ns-get", group("mygroup")),
+			roleBinding("myns", "by-authenticated", "ns-get", group("system:authenticated")),
+			roleBinding("myns", "by-other-group", "ns-get", group("othergroup")),
+		)
+
+		// then
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Groups).To(Equal([]string{"mygroup", "system:authenticated"}))
+		Expect(ex.Grants).To(HaveExactElements(
+			And(HaveField("Name", "by-authenticated"), HaveField("Group", "system:authenticated")),
+			And(HaveField("Name", "by-group"), HaveField("Group", "mygroup")),
+		))
+	})
+
+	It("returns the bindings granting access to the implicit groups of service accounts", func(ctx context.Context) {
+		// when
+		ex := explain(ctx, sa,
+			getNsClusterRole,
+			roleBinding("myns", "by-namespace-sa", "ns-get", rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:serviceaccounts:myns"}),
+		)
+
+		// then
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Groups).To(ConsistOf("system:authenticated", "system:serviceaccounts", "system:serviceaccounts:myns"))
+		Expect(ex.Grants).To(HaveExactElements(HaveField("Group", "system:serviceaccounts:myns")))
+	})
+
+	It("returns the closest bindings not granting access", func(ctx context.Context) {
+		// when
+		ex := explain(ctx, user,
+			getNsClusterRole,
+			listPodClusterRole,
+			roleBinding("myns", "pod-list", "pod-list", user),
+			roleBinding("myns", "missing", "missing", user),
+			roleBinding("otherns", "ns-get", "ns-get", user),
+			roleBinding("myns", "ns-get", "ns-get", rbacv1.Subject{Kind: rbacv1.UserKind, Name: "otheruser"}),
+			roleBinding("otherns", "other-ns-get", "ns-get", rbacv1.Subject{Kind: rbacv1.UserKind, Name: "otheruser"}),
+		)
+
+		// then
+		Expect(ex.Allowed).To(BeFalse())
+		Expect(ex.Grants).To(BeEmpty())
+		Expect(ex.Candidates).To(HaveExactElements(
+			And(HaveField("Name", "missing"), HaveField("Reason", `ClusterRole "missing" not found`)),
+			And(HaveField("Name", "pod-list"), HaveField("Reason", `ClusterRole "pod-list" does not grant get namespaces`), HaveField("Rules", ConsistOf(listPodRule))),
+			And(HaveField("Namespace", "otherns"), HaveField("Name", "ns-get"), HaveField("Reason", `bound in namespace "otherns"`)),
+			And(HaveField("Namespace", "myns"), HaveField("Name", "ns-get"), HaveField("Reason", "subject is not bound")),
+		))
+	})
+
+	It("reads live the roles not granting access, as they are not cached", func(ctx context.Context) {
+		// given
+		cached := fake.NewClientBuilder().WithObjects(
+			roleBinding("myns", "pod-list", "pod-list", user),
+			roleBinding("myns", "missing", "missing", user),
+		).Build()
+		live := fake.NewClientBuilder().WithObjects(listPodClusterRole).Build()
+		e := namespacelister.NewAccessExplainer(cached, live, resourcecache.AccessProfile{
+			Name:             resourcecache.DefaultAccessProfileName,
+			AccessAttributes: cache.DefaultAccessAttributes,
+		})
+
+		// when
+		ex, err := e.Explain(ctx, "", user, nil, "myns")
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ex.Allowed).To(BeFalse())
+		Expect(ex.Candidates).To(HaveExactElements(
+			And(HaveField("Name", "missing"), HaveField("Reason", `ClusterRole "missing" not found`)),
+			And(HaveField("Name", "pod-list"), HaveField("Reason", `ClusterRole "pod-list" does not grant get namespaces`), HaveField("Rules", ConsistOf(listPodRule))),
+		))
+	})
+
+	It("lists live each kind of role once", func(ctx context.Context) {
+		// given
+		cached := fake.NewClientBuilder().WithObjects(
+			roleBinding("myns", "pod-list", "pod-list", user),
+			roleBinding("myns", "other-pod-list", "pod-list", user),
+			roleBinding("myns", "missing", "missing", user),
+			&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "myns", Name: "role"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "pod-list"},
+				Subjects:   []rbacv1.Subject{user},
+			},
+		).Build()
+		calls := map[string]int{}
+		live := interceptor.NewClient(
+			fake.NewClientBuilder().WithObjects(listPodClusterRole).Build(),
+			interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					calls[fmt.Sprintf("get %T", obj)]++
+					return c.Get(ctx, key, obj, opts...)
+				},
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					calls[fmt.Sprintf("list %T", list)]++
+					return c.List(ctx, list, opts...)
+				},
+			})
+		e := namespacelister.NewAccessExplainer(cached, live, resourcecache.AccessProfile{
+			Name:             resourcecache.DefaultAccessProfileName,
+			AccessAttributes: cache.DefaultAccessAttributes,
+		})
+
+		// when
+		ex, err := e.Explain(ctx, "", user, nil, "myns")
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ex.Candidates).To(HaveLen(4))
+		Expect(calls).To(Equal(map[string]int{
+			"list *v1.ClusterRoleList": 1,
+			"list *v1.RoleList":        1,
+		}))
+	})
+
+	It("rejects unknown access profiles", func(ctx context.Context) {
+		// given
+		cli := fake.NewClientBuilder().Build()
+		e := namespacelister.NewAccessExplainer(cli, cli)
+
+		// when
+		_, err := e.Explain(ctx, "unknown", user, nil, "myns")
+
+		// then
+		Expect(kerrors.IsBadRequest(err)).To(BeTrue())
+	})
+})
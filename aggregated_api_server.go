@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	nlv1 "github.com/konflux-ci/namespace-lister/pkg/apis/namespacelister/v1"
+	"github.com/konflux-ci/namespace-lister/pkg/generated/openapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/component-base/compatibility"
+	baseversion "k8s.io/component-base/version"
+)
+
+var (
+	aggregatedScheme = runtime.NewScheme()
+	aggregatedCodecs = serializer.NewCodecFactory(aggregatedScheme)
+)
+
+func init() {
+	utilruntime.Must(nlv1.AddToScheme(aggregatedScheme))
+
+	// the types are served as is, so the internal version uses the same types
+	aggregatedScheme.AddKnownTypes(schema.GroupVersion{Group: nlv1.GroupName, Version: runtime.APIVersionInternal},
+		&nlv1.AccessibleNamespace{},
+		&nlv1.AccessibleNamespaceList{},
+	)
+
+	// the generic API server expects list, get, and watch options,
+	// as well as discovery types, to be registered in the empty group
+	metav1.AddToGroupVersion(aggregatedScheme, schema.GroupVersion{Version: "v1"})
+	unversioned := schema.GroupVersion{Group: "", Version: "v1"}
+	aggregatedScheme.AddUnversionedTypes(unversioned,
+		&metav1.Status{},
+		&metav1.APIVersions{},
+		&metav1.APIGroupList{},
+		&metav1.APIGroup{},
+		&metav1.APIResourceList{},
+	)
+}
+
+// AggregatedAPIServerOptions configures the AggregatedAPIServer
+type AggregatedAPIServerOptions struct {
+	// CertPath and KeyPath are the paths of the serving certificate and key.
+	// If not set, a self-signed certificate is generated.
+	CertPath string
+	KeyPath  string
+
+	// Lister serves the AccessibleNamespaces
+	Lister NamespaceLister
+
+	// ReadinessChecks report whether the server is ready to serve requests
+	ReadinessChecks []healthz.HealthChecker
+}
+
+// NewAggregatedAPIServer builds a Kubernetes aggregated API server serving the namespaces
+// the requesting user has access to as AccessibleNamespaces in the namespacelister.konflux-ci.dev group.
+// Requests are authenticated via front-proxy client certificates or TokenReviews,
+// and authorized via SubjectAccessReviews, delegating both to the Kubernetes APIServer.
+func NewAggregatedAPIServer(opts AggregatedAPIServerOptions) (*genericapiserver.GenericAPIServer, error) {
+	o, err := newAggregatedAPIServerRecommendedOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewAggregatedAPIServerConfig()
+	if err := o.ApplyTo(c); err != nil {
+		return nil, err
+	}
+
+	return NewAggregatedAPIServerFromConfig(c, opts)
+}
+
+// NewAggregatedAPIServerConfig builds the base configuration of the aggregated API server,
+// serving discovery and OpenAPI for the namespacelister.konflux-ci.dev group.
+// Serving, authentication, and authorization are left to be configured.
+func NewAggregatedAPIServerConfig() *genericapiserver.RecommendedConfig {
+	c := genericapiserver.NewRecommendedConfig(aggregatedCodecs)
+	// emulate the Kubernetes version of the k8s.io libraries the server is built with
+	c.EffectiveVersion = compatibility.NewEffectiveVersionFromString(baseversion.DefaultKubeBinaryVersion, "", "")
+	c.OpenAPIConfig = genericapiserver.DefaultOpenAPIConfig(openapi.GetOpenAPIDefinitions, openapinamer.NewDefinitionNamer(aggregatedScheme))
+	c.OpenAPIConfig.Info.Title = "Namespace-Lister"
+	c.OpenAPIV3Config = genericapiserver.DefaultOpenAPIV3Config(openapi.GetOpenAPIDefinitions, openapinamer.NewDefinitionNamer(aggregatedScheme))
+	c.OpenAPIV3Config.Info.Title = "Namespace-Lister"
+	return c
+}
+
+// NewAggregatedAPIServerFromConfig completes the provided configuration and
+// builds an aggregated API server serving the namespacelister.konflux-ci.dev group
+func NewAggregatedAPIServerFromConfig(c *genericapiserver.RecommendedConfig, opts AggregatedAPIServerOptions) (*genericapiserver.GenericAPIServer, error) {
+	c.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		return genericapiserver.DefaultBuildHandlerChain(addAccessProfileMiddleware(apiHandler), c)
+	}
+	c.AddReadyzChecks(opts.ReadinessChecks...)
+
+	s, err := c.Complete().New("namespace-lister", genericapiserver.NewEmptyDelegate())
+	if err != nil {
+		return nil, err
+	}
+
+	agi := genericapiserver.NewDefaultAPIGroupInfo(nlv1.GroupName, aggregatedScheme, metav1.ParameterCodec, aggregatedCodecs)
+	agi.VersionedResourcesStorageMap[nlv1.SchemeGroupVersion.Version] = map[string]rest.Storage{
+		resourceAccessibleNamespaces: newAccessibleNamespacesStorage(opts.Lister),
+	}
+	if err := s.InstallAPIGroup(&agi); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newAggregatedAPIServerRecommendedOptions configures the generic API server options.
+// As the server does not store any resource, etcd and admission are disabled.
+// The client configuration used to delegate authentication and authorization is read from the
+// KUBECONFIG environment variable, defaulting to the in-cluster one.
+func newAggregatedAPIServerRecommendedOptions(opts AggregatedAPIServerOptions) (*genericoptions.RecommendedOptions, error) {
+	o := genericoptions.NewRecommendedOptions("", aggregatedCodecs.LegacyCodec(nlv1.SchemeGroupVersion))
+	o.Etcd = nil
+	o.Admission = nil
+	o.CoreAPI = nil
+	o.Features.EnablePriorityAndFairness = false
+
+	kubeconfig := os.Getenv("KUBECONFIG")
+	o.Authentication.RemoteKubeConfigFile = kubeconfig
+	o.Authorization.RemoteKubeConfigFile = kubeconfig
+
+	host, port, err := net.SplitHostPort(getAddress())
+	if err != nil {
+		return nil, err
+	}
+	if o.SecureServing.BindPort, err = strconv.Atoi(port); err != nil {
+		return nil, err
+	}
+	if host != "" {
+		o.SecureServing.BindAddress = net.ParseIP(host)
+	}
+
+	// generate the self-signed certificate in memory, as the filesystem may be read-only
+	o.SecureServing.ServerCert.CertDirectory = ""
+	o.SecureServing.ServerCert.PairName = ""
+	if opts.CertPath != "" || opts.KeyPath != "" {
+		o.SecureServing.ServerCert.CertKey.CertFile = opts.CertPath
+		o.SecureServing.ServerCert.CertKey.KeyFile = opts.KeyPath
+	} else if err := o.SecureServing.MaybeDefaultWithSelfSignedCerts("localhost", nil, []net.IP{net.ParseIP("127.0.0.1")}); err != nil {
+		return nil, err
+	}
+
+	if errs := o.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return o, nil
+}
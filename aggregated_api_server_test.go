@@ -0,0 +1,174 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	nlv1 "github.com/konflux-ci/namespace-lister/pkg/apis/namespacelister/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("AggregatedAPIServer", func() {
+	const basePath = "/apis/namespacelister.konflux-ci.dev/v1/accessiblenamespaces"
+
+	var handler http.Handler
+	var lister NamespaceListerMock
+
+	nn := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "myns", Labels: map[string]string{"team": "a"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "otherns", Labels: map[string]string{"team": "b"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+	}
+
+	BeforeEach(func() {
+		lister = NamespaceListerMock{
+			ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				Expect(username).To(Equal("myuser"))
+				return &corev1.NamespaceList{ListMeta: metav1.ListMeta{ResourceVersion: "3"}, Items: nn}, nil
+			},
+			GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+				for _, ns := range nn {
+					if ns.Name == name {
+						return ns.DeepCopy(), "3", nil
+					}
+				}
+				return nil, "", kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+			},
+		}
+
+		c := namespacelister.NewAggregatedAPIServerConfig()
+		c.LoopbackClientConfig = &rest.Config{}
+		c.ExternalAddress = "localhost:8443"
+		c.Authentication.Authenticator = authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			if req.Header.Get("Authorization") == "" {
+				return nil, false, nil
+			}
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "myuser"}}, true, nil
+		})
+		c.Authorization.Authorizer = authorizerfactory.NewAlwaysAllowAuthorizer()
+
+		s, err := namespacelister.NewAggregatedAPIServerFromConfig(c, namespacelister.AggregatedAPIServerOptions{Lister: lister})
+		Expect(err).NotTo(HaveOccurred())
+		s.PrepareRun()
+		handler = s.Handler
+	})
+
+	get := func(ctx context.Context, path string, headers map[string]string) *http.Response {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		r.Header.Set("Authorization", "Bearer token")
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	It("serves the API group discovery", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, "/apis/namespacelister.konflux-ci.dev/v1", nil)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		rl := metav1.APIResourceList{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&rl)).To(Succeed())
+		Expect(rl.GroupVersion).To(Equal(nlv1.SchemeGroupVersion.String()))
+		Expect(rl.APIResources).To(ConsistOf(And(
+			HaveField("Name", "accessiblenamespaces"),
+			HaveField("SingularName", "accessiblenamespace"),
+			HaveField("Kind", "AccessibleNamespace"),
+			HaveField("Namespaced", false),
+			HaveField("Verbs", ConsistOf("get", "list", "watch")),
+		)))
+	})
+
+	It("serves the OpenAPI specification", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, "/openapi/v3/apis/namespacelister.konflux-ci.dev/v1", nil)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("lists the accessible namespaces", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, basePath+"?labelSelector=team%3Da", nil)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		l := nlv1.AccessibleNamespaceList{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&l)).To(Succeed())
+		Expect(l.Kind).To(Equal("AccessibleNamespaceList"))
+		Expect(l.APIVersion).To(Equal(nlv1.SchemeGroupVersion.String()))
+		Expect(l.ResourceVersion).To(Equal("3"))
+		Expect(l.Items).To(ConsistOf(And(
+			HaveField("Name", "myns"),
+			HaveField("Status.Phase", corev1.NamespaceActive),
+		)))
+	})
+
+	It("gets an accessible namespace", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, basePath+"/myns", nil)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		ns := nlv1.AccessibleNamespace{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&ns)).To(Succeed())
+		Expect(ns.Kind).To(Equal("AccessibleNamespace"))
+		Expect(ns.Name).To(Equal("myns"))
+	})
+
+	It("replies NotFound for namespaces not accessible", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, basePath+"/notaccessible", nil)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusNotFound))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&s)).To(Succeed())
+		Expect(s.Details).To(HaveField("Group", nlv1.GroupName))
+		Expect(s.Details).To(HaveField("Kind", "accessiblenamespaces"))
+	})
+
+	It("converts the accessible namespaces to tables", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, basePath, map[string]string{"Accept": "application/json;as=Table;v=v1;g=meta.k8s.io"})
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		t := metav1.Table{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&t)).To(Succeed())
+		Expect(t.ColumnDefinitions).To(HaveEach(HaveField("Name", Not(BeEmpty()))))
+		Expect(t.Rows).To(HaveLen(2))
+		Expect(t.Rows[0].Cells[0]).To(Equal("myns"))
+	})
+
+	It("rejects unauthenticated requests", func(ctx context.Context) {
+		// given
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, basePath, nil)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(strings.ToLower(w.Body.String())).To(ContainSubstring("unauthorized"))
+	})
+})
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	nlv1 "github.com/konflux-ci/namespace-lister/pkg/apis/namespacelister/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+const (
+	resourceAccessibleNamespaces         string = "accessiblenamespaces"
+	resourceAccessibleNamespacesSingular string = "accessiblenamespace"
+)
+
+var (
+	_ rest.Storage              = &accessibleNamespacesStorage{}
+	_ rest.Scoper               = &accessibleNamespacesStorage{}
+	_ rest.SingularNameProvider = &accessibleNamespacesStorage{}
+	_ rest.Lister               = &accessibleNamespacesStorage{}
+	_ rest.Getter               = &accessibleNamespacesStorage{}
+	_ rest.Watcher              = &accessibleNamespacesStorage{}
+)
+
+// accessibleNamespacesStorage serves the AccessibleNamespaces of the aggregated API
+// out of the namespaces the requesting user has access to
+type accessibleNamespacesStorage struct {
+	lister NamespaceLister
+}
+
+// newAccessibleNamespacesStorage builds the REST storage of AccessibleNamespaces
+func newAccessibleNamespacesStorage(lister NamespaceLister) *accessibleNamespacesStorage {
+	return &accessibleNamespacesStorage{lister: lister}
+}
+
+func (s *accessibleNamespacesStorage) New() runtime.Object {
+	return &nlv1.AccessibleNamespace{}
+}
+
+func (s *accessibleNamespacesStorage) NewList() runtime.Object {
+	return &nlv1.AccessibleNamespaceList{}
+}
+
+func (s *accessibleNamespacesStorage) Destroy() {}
+
+func (s *accessibleNamespacesStorage) NamespaceScoped() bool {
+	return false
+}
+
+func (s *accessibleNamespacesStorage) GetSingularName() string {
+	return resourceAccessibleNamespacesSingular
+}
+
+// List retrieves the AccessibleNamespaces of the requesting user,
+// applying label and field selectors as well as pagination
+func (s *accessibleNamespacesStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	u, err := userFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := newNamespaceFilter(options.LabelSelector, options.FieldSelector)
+	if err != nil {
+		return nil, kerrors.NewBadRequest(err.Error())
+	}
+
+	nn, err := s.lister.ListNamespaces(ctx, u.GetName(), u.GetGroups())
+	if err != nil {
+		return nil, err
+	}
+
+	nn.Items = f.Filter(nn.Items)
+	if err := paginate(nn, options.Limit, options.Continue); err != nil {
+		return nil, err
+	}
+
+	l := &nlv1.AccessibleNamespaceList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AccessibleNamespaceList",
+			APIVersion: nlv1.SchemeGroupVersion.String(),
+		},
+		ListMeta: nn.ListMeta,
+		Items:    make([]nlv1.AccessibleNamespace, 0, len(nn.Items)),
+	}
+	for _, ns := range nn.Items {
+		l.Items = append(l.Items, *nlv1.NewAccessibleNamespace(&ns))
+	}
+	return l, nil
+}
+
+// Get retrieves the AccessibleNamespace with the provided name if the requesting user has access to it
+func (s *accessibleNamespacesStorage) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	u, err := userFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ns, _, err := s.lister.GetNamespace(ctx, u.GetName(), u.GetGroups(), name)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, kerrors.NewNotFound(nlv1.Resource(resourceAccessibleNamespaces), name)
+		}
+		return nil, err
+	}
+	return nlv1.NewAccessibleNamespace(ns), nil
+}
+
+// Watch streams the changes on the AccessibleNamespaces of the requesting user
+func (s *accessibleNamespacesStorage) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	u, err := userFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := newNamespaceFilter(options.LabelSelector, options.FieldSelector)
+	if err != nil {
+		return nil, kerrors.NewBadRequest(err.Error())
+	}
+
+	w, err := s.lister.WatchNamespaces(ctx, u.GetName(), u.GetGroups())
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(f.FilterWatch(w), func(in watch.Event) (watch.Event, bool) {
+		if ns, ok := in.Object.(*corev1.Namespace); ok {
+			in.Object = nlv1.NewAccessibleNamespace(ns)
+		}
+		return in, true
+	}), nil
+}
+
+// ConvertToTable converts AccessibleNamespaces to a Table with the same columns
+// used for Namespaces
+func (s *accessibleNamespacesStorage) ConvertToTable(_ context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	t := &metav1.Table{}
+	if opts, ok := tableOptions.(*metav1.TableOptions); !ok || !opts.NoHeaders {
+		t.ColumnDefinitions = namespaceTableColumnDefinitions
+	}
+
+	var nn []nlv1.AccessibleNamespace
+	switch o := object.(type) {
+	case *nlv1.AccessibleNamespace:
+		nn = []nlv1.AccessibleNamespace{*o}
+	case *nlv1.AccessibleNamespaceList:
+		nn = o.Items
+		t.ListMeta = o.ListMeta
+	default:
+		return nil, fmt.Errorf("unsupported object type for table conversion: %T", object)
+	}
+
+	t.Rows = make([]metav1.TableRow, 0, len(nn))
+	for i := range nn {
+		t.Rows = append(t.Rows, metav1.TableRow{
+			Cells:  namespaceTableRowCells(&nn[i].ObjectMeta, nn[i].Status.Phase),
+			Object: runtime.RawExtension{Object: &nn[i]},
+		})
+	}
+	return t, nil
+}
+
+// userFrom retrieves the requesting user from the context
+func userFrom(ctx context.Context) (user.Info, error) {
+	u, ok := request.UserFrom(ctx)
+	if !ok {
+		return nil, kerrors.NewUnauthorized("no user found in the request")
+	}
+	return u, nil
+}
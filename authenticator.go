@@ -1,54 +1,155 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"errors"
+	"fmt"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/apis/apiserver"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authentication/group"
+	"k8s.io/apiserver/pkg/authentication/request/headerrequest"
 	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
 	authenticationv1 "k8s.io/client-go/kubernetes/typed/authentication/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/kube-openapi/pkg/validation/spec"
 )
 
-// Authenticator authenticates requests.
+// Authenticator authenticates requests with an ordered chain of authenticators.
+// Authenticators are evaluated in order, the first one authenticating the request wins.
+// The name of the authenticator that authenticated the request is added to the user's
+// extra information, and the authentication result is collected in metrics.
+//
 // If Header authentication is enabled, it will check the value in the request Header accordingly.
 // If the value in the header was provided, it assumes a proxy already authenticated the request.
+// As the header is trusted from any caller, RequestHeader authentication should be preferred.
+//
+// If RequestHeader authentication is enabled, the user information in the request headers
+// is accepted only if the request carries a client certificate signed by the configured
+// CA and, if any is configured, with an allowed Common Name.
+//
+// If client certificate authentication is enabled, requests carrying a client certificate
+// signed by the configured CA are authenticated as the certificate's subject:
+// the Common Name is used as username, and the Organizations as groups.
+//
+// If OIDC authentication is enabled, bearer tokens issued by the configured OIDC providers
+// are validated locally, without involving the APIServer.
 //
-// If header authentication is disabled or the header is not set in the request,
-// the request is authenticated by the DelegatingAuthenticator configured with
-// disabled anonymous access and enabled TokenAccessReview. This means it will look for a JWT
-// Token in the request and ask the APIServer to authenticate it.
+// If TokenReview authentication is enabled, the request is authenticated by the
+// DelegatingAuthenticator configured with disabled anonymous access and enabled TokenAccessReview.
+// This means it will look for a JWT Token in the request and ask the APIServer to authenticate it.
 // APIServer replies are cached for a short time.
 type Authenticator struct {
+	authenticators []namedAuthenticator
+	metrics        *authenticatorMetrics
+}
+
+// namedAuthenticator is an authenticator of the chain
+type namedAuthenticator struct {
+	name string
+	typ  AuthenticatorType
+	authenticator.Request
+}
+
+// AuthenticateRequest authenticates a request with the first authenticator of the chain
+// that is able to authenticate it.
+// If no authenticator is able to, the errors of all the authenticators are returned.
+func (a *Authenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
+	ctx, span := startSpan(req.Context(), "Authenticate")
+	req = req.WithContext(ctx)
+
+	var errs []error
+	for _, na := range a.authenticators {
+		rs, ok, err := na.AuthenticateRequest(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			a.metrics.collectAuthenticated(na.name)
+			span.SetAttributes(attribute.String(attributeAuthenticatorName, na.name))
+			endSpan(span, nil)
+			return withAuthenticatorName(rs, na.name), true, nil
+		}
+	}
+
+	a.metrics.collectUnauthenticated(len(errs) > 0)
+	err := utilerrors.NewAggregate(errs)
+	endSpan(span, err)
+	return nil, false, err
+}
+
+// AuthenticateRequest authenticates a request tracing the attempt in a dedicated span
+func (na *namedAuthenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
+	ctx, span := startSpan(req.Context(), "Authenticate "+string(na.typ), trace.WithAttributes(
+		attribute.String(attributeAuthenticatorName, na.name),
+		attribute.String(attributeAuthenticatorType, string(na.typ)),
+	))
+	rs, ok, err := na.Request.AuthenticateRequest(req.WithContext(ctx))
+	span.SetAttributes(attribute.Bool(attributeAuthenticated, ok))
+	endSpan(span, err)
+	return rs, ok, err
+}
+
+// withAuthenticatorName returns a copy of the response with the authenticator name
+// added to the user's extra information
+func withAuthenticatorName(rs *authenticator.Response, name string) *authenticator.Response {
+	if rs == nil || rs.User == nil {
+		return rs
+	}
+
+	extra := maps.Clone(rs.User.GetExtra())
+	if extra == nil {
+		extra = map[string][]string{}
+	}
+	extra[constants.UserExtraAuthenticator] = []string{name}
+
+	return &authenticator.Response{
+		Audiences: rs.Audiences,
+		User: &user.DefaultInfo{
+			Name:   rs.User.GetName(),
+			UID:    rs.User.GetUID(),
+			Groups: rs.User.GetGroups(),
+			Extra:  extra,
+		},
+	}
+}
+
+// headerAuthenticator authenticates requests trusting the user information in the request headers
+type headerAuthenticator struct {
 	usernameHeader string
 	groupsHeader   string
-	next           authenticator.Request
 }
 
 // AuthenticateRequest authenticates a request by checking the username header
-// and/or validating the token through the APIServer.
-func (a *Authenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
-	if a.usernameHeader != "" {
-		if username := req.Header.Get(a.usernameHeader); username != "" {
-			groups := req.Header.Values(a.groupsHeader)
-
-			return &authenticator.Response{
-				User: &user.DefaultInfo{
-					Name:   username,
-					Groups: groups,
-				},
-			}, true, nil
-		}
+func (a *headerAuthenticator) AuthenticateRequest(req *http.Request) (*authenticator.Response, bool, error) {
+	username := req.Header.Get(a.usernameHeader)
+	if username == "" {
+		return nil, false, nil
 	}
 
-	return a.next.AuthenticateRequest(req)
+	return &authenticator.Response{
+		User: &user.DefaultInfo{
+			Name:   username,
+			Groups: req.Header.Values(a.groupsHeader),
+		},
+	}, true, nil
 }
 
 // AuthenticatorOptions allows to configure the Authenticator
@@ -57,20 +158,156 @@ type AuthenticatorOptions struct {
 	Config         *rest.Config
 	UsernameHeader string
 	GroupsHeader   string
+
+	// RequestHeader enables the RequestHeader authentication, if set.
+	// It is mutually exclusive with Header authentication.
+	RequestHeader *RequestHeaderOptions
+
+	// ClientCert enables the client certificate authentication, if set
+	ClientCert *ClientCertOptions
+
+	// OIDC enables the local validation of the tokens issued by OIDC providers, if set
+	OIDC *OIDCOptions
+
+	// Configuration declares the chain of authenticators, if set.
+	// It is mutually exclusive with the options of the single authenticators.
+	Configuration *AuthenticationConfiguration
+
+	// Registerer is used to register the authentication metrics, if set
+	Registerer prometheus.Registerer
 }
 
-// NewAuthenticator builds a new Authenticator
-func NewAuthenticator(opts AuthenticatorOptions) (authenticator.Request, error) {
-	ar, _, err := newTokenReviewAuthenticatorWithOpts(&opts)
+// ClientCertOptions allows to configure the client certificate authentication
+type ClientCertOptions struct {
+	// ClientCAFile is the path of the CA bundle used to verify the client certificates
+	ClientCAFile string `json:"clientCAFile"`
+}
+
+// RequestHeaderOptions allows to configure the RequestHeader authentication
+type RequestHeaderOptions struct {
+	// ClientCAFile is the path of the CA bundle used to verify the client certificates
+	ClientCAFile string `json:"clientCAFile"`
+	// AllowedNames are the Common Names the client certificates are allowed to have.
+	// If empty, any client certificate signed by the CA is accepted.
+	AllowedNames []string `json:"allowedNames,omitempty"`
+
+	// UsernameHeaders are the headers to check, in order, for the username
+	UsernameHeaders []string `json:"usernameHeaders,omitempty"`
+	// UIDHeaders are the headers to check, in order, for the user's UID
+	UIDHeaders []string `json:"uidHeaders,omitempty"`
+	// GroupHeaders are the headers to check for the user's groups
+	GroupHeaders []string `json:"groupHeaders,omitempty"`
+	// ExtraHeaderPrefixes are the prefixes of the headers to check for the user's extra information
+	ExtraHeaderPrefixes []string `json:"extraHeaderPrefixes,omitempty"`
+}
+
+// AuthenticationConfiguration returns the chain of authenticators declared by the options.
+// If no chain is explicitly configured, it is built from the options of the single authenticators
+// in the following order: Header, RequestHeader, ClientCert, OIDC, and TokenReview.
+func (opts *AuthenticatorOptions) AuthenticationConfiguration() (*AuthenticationConfiguration, error) {
+	if opts.Configuration != nil {
+		if opts.UsernameHeader != "" || opts.RequestHeader != nil || opts.ClientCert != nil || opts.OIDC != nil {
+			return nil, errors.New("authentication configuration and single authenticators options are mutually exclusive")
+		}
+		c := *opts.Configuration
+		c.Authenticators = slices.Clone(c.Authenticators)
+		c.Default()
+		return &c, c.Validate()
+	}
+
+	if opts.RequestHeader != nil && opts.UsernameHeader != "" {
+		return nil, errors.New("header and request header authentication are mutually exclusive")
+	}
+
+	c := &AuthenticationConfiguration{}
+	if opts.UsernameHeader != "" {
+		c.Authenticators = append(c.Authenticators, AuthenticatorConfiguration{
+			Type:   AuthenticatorTypeHeader,
+			Header: &HeaderOptions{UsernameHeader: opts.UsernameHeader, GroupsHeader: opts.GroupsHeader},
+		})
+	}
+	if opts.RequestHeader != nil {
+		c.Authenticators = append(c.Authenticators, AuthenticatorConfiguration{Type: AuthenticatorTypeRequestHeader, RequestHeader: opts.RequestHeader})
+	}
+	if opts.ClientCert != nil {
+		c.Authenticators = append(c.Authenticators, AuthenticatorConfiguration{Type: AuthenticatorTypeClientCert, ClientCert: opts.ClientCert})
+	}
+	if opts.OIDC != nil {
+		c.Authenticators = append(c.Authenticators, AuthenticatorConfiguration{Type: AuthenticatorTypeOIDC, OIDC: opts.OIDC})
+	}
+	c.Authenticators = append(c.Authenticators, AuthenticatorConfiguration{Type: AuthenticatorTypeTokenReview})
+	c.Default()
+	return c, c.Validate()
+}
+
+// NewAuthenticator builds a new Authenticator.
+// The provided context bounds the lifecycle of the authenticators' background routines.
+func NewAuthenticator(ctx context.Context, opts AuthenticatorOptions) (authenticator.Request, error) {
+	c, err := opts.AuthenticationConfiguration()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Authenticator{
-		usernameHeader: opts.UsernameHeader,
-		groupsHeader:   opts.GroupsHeader,
-		next:           ar,
-	}, nil
+	m, err := newAuthenticatorMetrics(opts.Registerer)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Authenticator{metrics: m}
+	for _, ac := range c.Authenticators {
+		ar, err := newChainedAuthenticator(ctx, &opts, ac)
+		if err != nil {
+			return nil, fmt.Errorf("error building authenticator %q: %w", ac.Name, err)
+		}
+		a.authenticators = append(a.authenticators, namedAuthenticator{name: ac.Name, typ: ac.Type, Request: ar})
+	}
+	return a, nil
+}
+
+// newChainedAuthenticator builds the authenticator declared in the provided configuration.
+// Except for the Header one, authenticators add the system:authenticated group to the users.
+func newChainedAuthenticator(ctx context.Context, opts *AuthenticatorOptions, c AuthenticatorConfiguration) (authenticator.Request, error) {
+	switch c.Type {
+	case AuthenticatorTypeHeader:
+		return &headerAuthenticator{
+			usernameHeader: c.Header.UsernameHeader,
+			groupsHeader:   c.Header.GroupsHeader,
+		}, nil
+
+	case AuthenticatorTypeRequestHeader:
+		rhc, err := newRequestHeaderConfig(c.RequestHeader)
+		if err != nil {
+			return nil, err
+		}
+		ar, _, err := authenticatorfactory.DelegatingAuthenticatorConfig{RequestHeaderConfig: rhc}.New()
+		return ar, err
+
+	case AuthenticatorTypeClientCert:
+		ccp, err := newStaticCAContentFromFile("client-ca", c.ClientCert.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		ar, _, err := authenticatorfactory.DelegatingAuthenticatorConfig{ClientCertificateCAContentProvider: ccp}.New()
+		return ar, err
+
+	case AuthenticatorTypeOIDC:
+		ar, err := NewOIDCAuthenticator(ctx, *c.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		return group.NewAuthenticatedGroupAdder(ar), nil
+
+	case AuthenticatorTypeTokenReview:
+		tc, err := newTokenAccessReviewClientWithOpts(opts)
+		if err != nil {
+			return nil, err
+		}
+		ar, _, err := newTokenReviewAuthenticatorWithOptions(tc, *c.TokenReview)
+		return ar, err
+
+	default:
+		return nil, fmt.Errorf("unknown authenticator type %q", c.Type)
+	}
 }
 
 // NewTokenReviewAuthenticatorWithClient builds a TokenReviewAuthenticator from a kubernetes client
@@ -87,27 +324,70 @@ func NewTokenReviewAuthenticatorWithConfig(cfg *rest.Config) (authenticator.Requ
 }
 
 func newTokenReviewAuthenticator(authenticationClient *authenticationv1.AuthenticationV1Client) (authenticator.Request, *spec.SecurityDefinitions, error) {
+	return newTokenReviewAuthenticatorWithOptions(authenticationClient, TokenReviewOptions{
+		CacheTTL: &metav1.Duration{Duration: defaultTokenReviewCacheTTL},
+		Timeout:  &metav1.Duration{Duration: defaultTokenReviewTimeout},
+	})
+}
+
+func newTokenReviewAuthenticatorWithOptions(authenticationClient *authenticationv1.AuthenticationV1Client, opts TokenReviewOptions) (authenticator.Request, *spec.SecurityDefinitions, error) {
 	authCfg := authenticatorfactory.DelegatingAuthenticatorConfig{
 		Anonymous:                &apiserver.AnonymousAuthConfig{Enabled: false},
 		TokenAccessReviewClient:  authenticationClient,
-		TokenAccessReviewTimeout: 1 * time.Minute,
+		TokenAccessReviewTimeout: opts.Timeout.Duration,
 		WebhookRetryBackoff:      &wait.Backoff{Duration: 2 * time.Second, Cap: 2 * time.Minute, Steps: 100, Factor: 2, Jitter: 2},
-		CacheTTL:                 5 * time.Minute,
+		CacheTTL:                 opts.CacheTTL.Duration,
 	}
 	return authCfg.New()
 }
 
-func newTokenReviewAuthenticatorWithOpts(opts *AuthenticatorOptions) (authenticator.Request, *spec.SecurityDefinitions, error) {
+func newTokenAccessReviewClientWithOpts(opts *AuthenticatorOptions) (*authenticationv1.AuthenticationV1Client, error) {
 	switch {
 	case opts.Client != nil:
-		return NewTokenReviewAuthenticatorWithClient(opts.Client)
+		return authenticationv1.New(opts.Client), nil
 	case opts.Config != nil:
-		return NewTokenReviewAuthenticatorWithConfig(opts.Config)
+		return authenticationv1.NewForConfig(rest.CopyConfig(opts.Config))
 	default:
-		return nil, nil, errors.New("one among client and config is required to build the TokenRevierAuthenticator")
+		return nil, errors.New("one among client and config is required to build the TokenRevierAuthenticator")
 	}
 }
 
+// newRequestHeaderConfig builds the RequestHeader authentication configuration.
+// If no options are provided, nil is returned.
+func newRequestHeaderConfig(opts *RequestHeaderOptions) (*authenticatorfactory.RequestHeaderConfig, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	cp, err := newStaticCAContentFromFile("request-header", opts.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authenticatorfactory.RequestHeaderConfig{
+		CAContentProvider:   cp,
+		AllowedClientNames:  headerrequest.StaticStringSlice(opts.AllowedNames),
+		UsernameHeaders:     headerrequest.StaticStringSlice(opts.UsernameHeaders),
+		UIDHeaders:          headerrequest.StaticStringSlice(opts.UIDHeaders),
+		GroupHeaders:        headerrequest.StaticStringSlice(opts.GroupHeaders),
+		ExtraHeaderPrefixes: headerrequest.StaticStringSlice(opts.ExtraHeaderPrefixes),
+	}, nil
+}
+
+// newStaticCAContentFromFile reads the CA bundle stored at the provided path.
+// The CA bundle is read once, changes to the file are not reloaded.
+func newStaticCAContentFromFile(purpose, path string) (dynamiccertificates.CAContentProvider, error) {
+	ca, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s file: %w", purpose, err)
+	}
+	cp, err := dynamiccertificates.NewStaticCAContent(purpose, ca)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file %q: %w", purpose, path, err)
+	}
+	return cp, nil
+}
+
 // GetUsernameHeaderFromEnv retrieves from environment variable the name of the header
 // to use when authenticating requests by username header to extract user's username
 func GetUsernameHeaderFromEnv() string {
@@ -119,3 +399,50 @@ func GetUsernameHeaderFromEnv() string {
 func GetGroupsHeaderFromEnv() string {
 	return os.Getenv(constants.EnvGroupsHeader)
 }
+
+// GetRequestHeaderOptionsFromEnv retrieves from environment variables the configuration
+// of the RequestHeader authentication.
+// If no client CA file is configured, RequestHeader authentication is disabled and nil is returned.
+func GetRequestHeaderOptionsFromEnv() *RequestHeaderOptions {
+	caFile := os.Getenv(constants.EnvRequestHeaderClientCAFile)
+	if caFile == "" {
+		return nil
+	}
+
+	return &RequestHeaderOptions{
+		ClientCAFile:        caFile,
+		AllowedNames:        splitCommaSeparatedEnv(constants.EnvRequestHeaderAllowedNames, ""),
+		UsernameHeaders:     splitCommaSeparatedEnv(constants.EnvRequestHeaderUsernameHeaders, constants.DefaultRequestHeaderUsernameHeader),
+		UIDHeaders:          splitCommaSeparatedEnv(constants.EnvRequestHeaderUIDHeaders, ""),
+		GroupHeaders:        splitCommaSeparatedEnv(constants.EnvRequestHeaderGroupHeaders, constants.DefaultRequestHeaderGroupHeader),
+		ExtraHeaderPrefixes: splitCommaSeparatedEnv(constants.EnvRequestHeaderExtraHeadersPrefix, constants.DefaultRequestHeaderExtraHeadersPrefix),
+	}
+}
+
+// GetClientCertOptionsFromEnv retrieves from environment variable the configuration
+// of the client certificate authentication.
+// If no client CA file is configured, client certificate authentication is disabled and nil is returned.
+func GetClientCertOptionsFromEnv() *ClientCertOptions {
+	caFile := os.Getenv(constants.EnvClientCAFile)
+	if caFile == "" {
+		return nil
+	}
+	return &ClientCertOptions{ClientCAFile: caFile}
+}
+
+// splitCommaSeparatedEnv retrieves the comma separated values of the provided environment variable.
+// If the environment variable is not set, the default value is used.
+func splitCommaSeparatedEnv(key, defaultValue string) []string {
+	v := cmp.Or(os.Getenv(key), defaultValue)
+	if v == "" {
+		return nil
+	}
+
+	vv := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			vv = append(vv, s)
+		}
+	}
+	return vv
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// AuthenticatorType is the type of an authenticator in the authentication chain
+type AuthenticatorType string
+
+const (
+	// AuthenticatorTypeHeader trusts the user information in the request headers from any caller
+	AuthenticatorTypeHeader AuthenticatorType = "Header"
+	// AuthenticatorTypeRequestHeader trusts the user information in the request headers
+	// only from callers presenting a client certificate signed by the configured CA
+	AuthenticatorTypeRequestHeader AuthenticatorType = "RequestHeader"
+	// AuthenticatorTypeClientCert authenticates callers as the subject of their client certificate
+	AuthenticatorTypeClientCert AuthenticatorType = "ClientCert"
+	// AuthenticatorTypeOIDC validates locally the tokens issued by OIDC providers
+	AuthenticatorTypeOIDC AuthenticatorType = "OIDC"
+	// AuthenticatorTypeTokenReview validates tokens via TokenAccessReviews
+	AuthenticatorTypeTokenReview AuthenticatorType = "TokenReview"
+)
+
+const (
+	defaultTokenReviewCacheTTL = 5 * time.Minute
+	defaultTokenReviewTimeout  = 1 * time.Minute
+)
+
+// AuthenticationConfiguration declares the ordered chain of authenticators requests are authenticated with.
+// Authenticators are evaluated in order, the first one authenticating the request wins.
+type AuthenticationConfiguration struct {
+	Authenticators []AuthenticatorConfiguration `json:"authenticators"`
+}
+
+// AuthenticatorConfiguration declares an authenticator of the chain.
+// Only the options matching the authenticator's type can be set.
+type AuthenticatorConfiguration struct {
+	// Name identifies the authenticator in logs and metrics.
+	// It defaults to the lowercase type.
+	Name string `json:"name,omitempty"`
+	// Type is the type of the authenticator
+	Type AuthenticatorType `json:"type"`
+
+	Header        *HeaderOptions        `json:"header,omitempty"`
+	RequestHeader *RequestHeaderOptions `json:"requestHeader,omitempty"`
+	ClientCert    *ClientCertOptions    `json:"clientCert,omitempty"`
+	OIDC          *OIDCOptions          `json:"oidc,omitempty"`
+	TokenReview   *TokenReviewOptions   `json:"tokenReview,omitempty"`
+}
+
+// HeaderOptions allows to configure the Header authentication
+type HeaderOptions struct {
+	// UsernameHeader is the header to check for the username
+	UsernameHeader string `json:"usernameHeader"`
+	// GroupsHeader is the header to check for the user's groups
+	GroupsHeader string `json:"groupsHeader,omitempty"`
+}
+
+// TokenReviewOptions allows to configure the validation of tokens via TokenAccessReviews
+type TokenReviewOptions struct {
+	// CacheTTL is the duration the APIServer replies are cached for. Defaults to 5m.
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+	// Timeout is the timeout of the TokenAccessReview requests. Defaults to 1m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// RequiresClientCertificates returns true if any of the authenticators
+// in the chain verifies the requests' client certificates
+func (c *AuthenticationConfiguration) RequiresClientCertificates() bool {
+	return slices.ContainsFunc(c.Authenticators, func(a AuthenticatorConfiguration) bool {
+		return a.Type == AuthenticatorTypeRequestHeader || a.Type == AuthenticatorTypeClientCert
+	})
+}
+
+// Has returns true if the chain contains an authenticator of the provided type
+func (c *AuthenticationConfiguration) Has(t AuthenticatorType) bool {
+	return slices.ContainsFunc(c.Authenticators, func(a AuthenticatorConfiguration) bool {
+		return a.Type == t
+	})
+}
+
+// Default sets the default values of the authenticators' names and options
+func (c *AuthenticationConfiguration) Default() {
+	for i := range c.Authenticators {
+		a := &c.Authenticators[i]
+		if a.Name == "" {
+			a.Name = strings.ToLower(string(a.Type))
+		}
+
+		switch a.Type {
+		case AuthenticatorTypeRequestHeader:
+			if a.RequestHeader == nil {
+				continue
+			}
+			if len(a.RequestHeader.UsernameHeaders) == 0 {
+				a.RequestHeader.UsernameHeaders = []string{constants.DefaultRequestHeaderUsernameHeader}
+			}
+			if len(a.RequestHeader.GroupHeaders) == 0 {
+				a.RequestHeader.GroupHeaders = []string{constants.DefaultRequestHeaderGroupHeader}
+			}
+			if len(a.RequestHeader.ExtraHeaderPrefixes) == 0 {
+				a.RequestHeader.ExtraHeaderPrefixes = []string{constants.DefaultRequestHeaderExtraHeadersPrefix}
+			}
+		case AuthenticatorTypeTokenReview:
+			if a.TokenReview == nil {
+				a.TokenReview = &TokenReviewOptions{}
+			}
+			if a.TokenReview.CacheTTL == nil {
+				a.TokenReview.CacheTTL = &metav1.Duration{Duration: defaultTokenReviewCacheTTL}
+			}
+			if a.TokenReview.Timeout == nil {
+				a.TokenReview.Timeout = &metav1.Duration{Duration: defaultTokenReviewTimeout}
+			}
+		}
+	}
+}
+
+// Validate checks the chain is not empty, that the authenticators' names are unique
+// DNS-1123 labels, and that each authenticator only sets the options matching its type
+func (c *AuthenticationConfiguration) Validate() error {
+	if len(c.Authenticators) == 0 {
+		return errors.New("at least one authenticator is required")
+	}
+
+	names := map[string]struct{}{}
+	for i, a := range c.Authenticators {
+		if errs := validation.IsDNS1123Label(a.Name); len(errs) > 0 {
+			return fmt.Errorf("authenticators[%d]: invalid name %q: %s", i, a.Name, strings.Join(errs, ", "))
+		}
+		if _, ok := names[a.Name]; ok {
+			return fmt.Errorf("authenticators[%d]: duplicated name %q", i, a.Name)
+		}
+		names[a.Name] = struct{}{}
+
+		if err := a.validateOptions(); err != nil {
+			return fmt.Errorf("authenticators[%d] %q: %w", i, a.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateOptions checks that only the options matching the authenticator's type are set
+func (a *AuthenticatorConfiguration) validateOptions() error {
+	set := map[AuthenticatorType]bool{
+		AuthenticatorTypeHeader:        a.Header != nil,
+		AuthenticatorTypeRequestHeader: a.RequestHeader != nil,
+		AuthenticatorTypeClientCert:    a.ClientCert != nil,
+		AuthenticatorTypeOIDC:          a.OIDC != nil,
+		AuthenticatorTypeTokenReview:   a.TokenReview != nil,
+	}
+	if _, ok := set[a.Type]; !ok {
+		return fmt.Errorf("unknown type %q", a.Type)
+	}
+
+	for t, ok := range set {
+		switch {
+		case t == a.Type && !ok && t != AuthenticatorTypeTokenReview:
+			return fmt.Errorf("%s options are required", t)
+		case t != a.Type && ok:
+			return fmt.Errorf("%s options are not allowed for type %s", t, a.Type)
+		}
+	}
+
+	switch {
+	case a.Header != nil && a.Header.UsernameHeader == "":
+		return errors.New("username header is required")
+	case a.RequestHeader != nil && a.RequestHeader.ClientCAFile == "":
+		return errors.New("client CA file is required")
+	case a.ClientCert != nil && a.ClientCert.ClientCAFile == "":
+		return errors.New("client CA file is required")
+	}
+	return nil
+}
+
+// GetAuthenticationConfigurationFromEnv retrieves from environment variable the path of the
+// file declaring the authentication chain, and reads it.
+// If the environment variable is not set, nil is returned.
+func GetAuthenticationConfigurationFromEnv() (*AuthenticationConfiguration, error) {
+	p := os.Getenv(constants.EnvAuthConfigFile)
+	if p == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read authentication configuration file: %w", err)
+	}
+
+	c := AuthenticationConfiguration{}
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid authentication configuration file %q: %w", p, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,228 @@
+package main_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/mocks"
+)
+
+var _ = Describe("AuthenticationConfiguration", func() {
+	It("is read from file", func() {
+		// given
+		p := filepath.Join(GinkgoT().TempDir(), "authn.yaml")
+		Expect(os.WriteFile(p, []byte(`
+authenticators:
+- type: Header
+  header:
+    usernameHeader: X-User
+    groupsHeader: X-Groups
+- name: front-proxy
+  type: RequestHeader
+  requestHeader:
+    clientCAFile: /var/ca.crt
+    allowedNames: [front-proxy]
+- type: TokenReview
+  tokenReview:
+    cacheTTL: 30s
+`), 0o600)).To(Succeed())
+		GinkgoT().Setenv(constants.EnvAuthConfigFile, p)
+
+		// when
+		c, err := namespacelister.GetAuthenticationConfigurationFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		c.Default()
+
+		// then
+		Expect(c.Validate()).To(Succeed())
+		Expect(c.Authenticators).To(HaveExactElements(
+			And(
+				HaveField("Name", "header"),
+				HaveField("Header.UsernameHeader", "X-User"),
+				HaveField("Header.GroupsHeader", "X-Groups"),
+			),
+			And(
+				HaveField("Name", "front-proxy"),
+				HaveField("RequestHeader.AllowedNames", ConsistOf("front-proxy")),
+				HaveField("RequestHeader.UsernameHeaders", ConsistOf(constants.DefaultRequestHeaderUsernameHeader)),
+			),
+			And(
+				HaveField("Name", "tokenreview"),
+				HaveField("TokenReview.CacheTTL.Duration", 30*time.Second),
+				HaveField("TokenReview.Timeout.Duration", time.Minute),
+			),
+		))
+		Expect(c.RequiresClientCertificates()).To(BeTrue())
+	})
+
+	It("rejects unknown fields", func() {
+		// given
+		p := filepath.Join(GinkgoT().TempDir(), "authn.yaml")
+		Expect(os.WriteFile(p, []byte(`
+authenticators:
+- type: TokenReview
+  unknown: true
+`), 0o600)).To(Succeed())
+		GinkgoT().Setenv(constants.EnvAuthConfigFile, p)
+
+		// when
+		_, err := namespacelister.GetAuthenticationConfigurationFromEnv()
+
+		// then
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("rejects invalid configurations", func(c namespacelister.AuthenticationConfiguration, expectedErr string) {
+		// when
+		c.Default()
+		err := c.Validate()
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+	},
+		Entry("without authenticators",
+			namespacelister.AuthenticationConfiguration{},
+			"at least one authenticator is required"),
+		Entry("with duplicated names",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{Type: namespacelister.AuthenticatorTypeTokenReview},
+				{Type: namespacelister.AuthenticatorTypeTokenReview},
+			}},
+			`duplicated name "tokenreview"`),
+		Entry("with invalid name",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{Name: "Invalid_Name", Type: namespacelister.AuthenticatorTypeTokenReview},
+			}},
+			`invalid name "Invalid_Name"`),
+		Entry("with unknown type",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{Name: "unknown", Type: "Unknown"},
+			}},
+			`unknown type "Unknown"`),
+		Entry("without the options of its type",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{Type: namespacelister.AuthenticatorTypeOIDC},
+			}},
+			"OIDC options are required"),
+		Entry("with the options of another type",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{
+					Type:       namespacelister.AuthenticatorTypeHeader,
+					Header:     &namespacelister.HeaderOptions{UsernameHeader: "X-User"},
+					ClientCert: &namespacelister.ClientCertOptions{ClientCAFile: "ca.crt"},
+				},
+			}},
+			"ClientCert options are not allowed for type Header"),
+		Entry("with Header options missing the username header",
+			namespacelister.AuthenticationConfiguration{Authenticators: []namespacelister.AuthenticatorConfiguration{
+				{Type: namespacelister.AuthenticatorTypeHeader, Header: &namespacelister.HeaderOptions{}},
+			}},
+			"username header is required"),
+	)
+})
+
+var _ = Describe("Authenticator chain", func() {
+	var (
+		auth authenticator.Request
+		reg  *prometheus.Registry
+		c    *mocks.MockFakeInterface
+	)
+
+	BeforeEach(func(ctx context.Context) {
+		reg = prometheus.NewRegistry()
+		c = mocks.NewMockFakeInterface(gomock.NewController(GinkgoT()))
+
+		a, err := namespacelister.NewAuthenticator(ctx, namespacelister.AuthenticatorOptions{
+			Client:     c,
+			Registerer: reg,
+			Configuration: &namespacelister.AuthenticationConfiguration{
+				Authenticators: []namespacelister.AuthenticatorConfiguration{
+					{
+						Name:   "proxy-a",
+						Type:   namespacelister.AuthenticatorTypeHeader,
+						Header: &namespacelister.HeaderOptions{UsernameHeader: "X-User-A"},
+					},
+					{
+						Name:   "proxy-b",
+						Type:   namespacelister.AuthenticatorTypeHeader,
+						Header: &namespacelister.HeaderOptions{UsernameHeader: "X-User-B"},
+					},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		auth = a
+	})
+
+	DescribeTable("authenticates with the first matching authenticator", func(ctx context.Context, headers map[string]string, expectedUser, expectedAuthenticator string) {
+		// given
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+
+		// when
+		rs, ok, err := auth.AuthenticateRequest(r)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(rs.User.GetName()).To(Equal(expectedUser))
+		Expect(rs.User.GetExtra()).To(HaveKeyWithValue(constants.UserExtraAuthenticator, []string{expectedAuthenticator}))
+		Expect(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP namespace_lister_authn_requests_total authenticated requests by authenticator and result
+# TYPE namespace_lister_authn_requests_total counter
+namespace_lister_authn_requests_total{authenticator="`+expectedAuthenticator+`",result="authenticated"} 1
+`), "namespace_lister_authn_requests_total")).To(Succeed())
+	},
+		Entry("first authenticator", map[string]string{"X-User-A": "user-a", "X-User-B": "user-b"}, "user-a", "proxy-a"),
+		Entry("second authenticator", map[string]string{"X-User-B": "user-b"}, "user-b", "proxy-b"),
+	)
+
+	It("counts unauthenticated requests", func(ctx context.Context) {
+		// given
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		// when
+		rs, ok, err := auth.AuthenticateRequest(r)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(rs).To(BeNil())
+		Expect(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP namespace_lister_authn_requests_total authenticated requests by authenticator and result
+# TYPE namespace_lister_authn_requests_total counter
+namespace_lister_authn_requests_total{authenticator="",result="unauthenticated"} 1
+`), "namespace_lister_authn_requests_total")).To(Succeed())
+	})
+
+	It("rejects single authenticator options together with the configuration", func(ctx context.Context) {
+		// when
+		_, err := namespacelister.NewAuthenticator(ctx, namespacelister.AuthenticatorOptions{
+			Client:         c,
+			UsernameHeader: "X-User",
+			Configuration: &namespacelister.AuthenticationConfiguration{
+				Authenticators: []namespacelister.AuthenticatorConfiguration{{Type: namespacelister.AuthenticatorTypeTokenReview}},
+			},
+		})
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("mutually exclusive")))
+	})
+})
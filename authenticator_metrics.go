@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	authenticationResultAuthenticated   string = "authenticated"
+	authenticationResultUnauthenticated string = "unauthenticated"
+	authenticationResultError           string = "error"
+)
+
+// authenticatorMetrics collects the Authenticator's metrics.
+// A nil authenticatorMetrics collects nothing.
+type authenticatorMetrics struct {
+	// requests counts the authenticated requests by authenticator and result
+	requests *prometheus.CounterVec
+}
+
+// newAuthenticatorMetrics builds and registers the Authenticator's metrics.
+// If no registerer is provided, metrics are disabled and nil is returned.
+func newAuthenticatorMetrics(reg prometheus.Registerer) (*authenticatorMetrics, error) {
+	if reg == nil {
+		return nil, nil
+	}
+
+	m := &authenticatorMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "namespace_lister",
+			Subsystem: "authn",
+			Name:      "requests_total",
+			Help:      "authenticated requests by authenticator and result",
+		}, []string{
+			"authenticator",
+			"result",
+		}),
+	}
+	if err := reg.Register(m.requests); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// collectAuthenticated counts a request authenticated by the provided authenticator
+func (m *authenticatorMetrics) collectAuthenticated(name string) {
+	if m == nil {
+		return
+	}
+	m.requests.With(prometheus.Labels{"authenticator": name, "result": authenticationResultAuthenticated}).Inc()
+}
+
+// collectUnauthenticated counts a request no authenticator has authenticated
+func (m *authenticatorMetrics) collectUnauthenticated(failed bool) {
+	if m == nil {
+		return
+	}
+
+	r := authenticationResultUnauthenticated
+	if failed {
+		r = authenticationResultError
+	}
+	m.requests.With(prometheus.Labels{"authenticator": "", "result": r}).Inc()
+}
@@ -2,7 +2,18 @@ package main_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -32,7 +43,7 @@ var _ = Describe("Authenticator", func() {
 		BeforeEach(func() {
 			// given
 			c = mocks.NewMockFakeInterface(ctrl)
-			a, err := namespacelister.NewAuthenticator(namespacelister.AuthenticatorOptions{
+			a, err := namespacelister.NewAuthenticator(context.Background(), namespacelister.AuthenticatorOptions{
 				Client:         c,
 				UsernameHeader: userHeaderKey,
 				GroupsHeader:   groupsHeaderKey,
@@ -65,11 +76,159 @@ var _ = Describe("Authenticator", func() {
 		})
 	})
 
+	When("RequestHeader authentication is enabled", func() {
+		var (
+			caCert *x509.Certificate
+			caKey  *ecdsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			// given
+			caCert, caKey = newCA("front-proxy-ca")
+			caFile := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+			Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0o600)).To(Succeed())
+
+			c = mocks.NewMockFakeInterface(ctrl)
+			a, err := namespacelister.NewAuthenticator(context.Background(), namespacelister.AuthenticatorOptions{
+				Client: c,
+				RequestHeader: &namespacelister.RequestHeaderOptions{
+					ClientCAFile:        caFile,
+					AllowedNames:        []string{"front-proxy"},
+					UsernameHeaders:     []string{"X-Remote-User"},
+					GroupHeaders:        []string{"X-Remote-Group"},
+					ExtraHeaderPrefixes: []string{"X-Remote-Extra-"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			auth = a
+		})
+
+		newRequest := func(ctx context.Context, clientCert *x509.Certificate) *http.Request {
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			r.Header.Add("X-Remote-User", userHeaderValue)
+			r.Header.Add("X-Remote-Group", "group1")
+			r.Header.Add("X-Remote-Group", "group2")
+			r.Header.Add("X-Remote-Extra-Scopes", "scope1")
+			if clientCert != nil {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{clientCert}}
+			}
+			return r
+		}
+
+		It("returns user info from headers if the client certificate is valid", func(ctx context.Context) {
+			// given
+			r := newRequest(ctx, newClientCert(caCert, caKey, "front-proxy"))
+
+			// when
+			rs, ok, err := auth.AuthenticateRequest(r)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(rs.User.GetName()).To(Equal(userHeaderValue))
+			Expect(rs.User.GetGroups()).To(ContainElements("group1", "group2"))
+			Expect(rs.User.GetExtra()).To(HaveKeyWithValue("scopes", []string{"scope1"}))
+		})
+
+		DescribeTable("ignores user info from headers", func(ctx context.Context, clientCert func() *x509.Certificate) {
+			// given
+			r := newRequest(ctx, clientCert())
+
+			// when
+			rs, ok, _ := auth.AuthenticateRequest(r)
+
+			// then
+			Expect(ok).To(BeFalse())
+			Expect(rs).To(BeNil())
+		},
+			Entry("without client certificate", func() *x509.Certificate { return nil }),
+			Entry("with client certificate signed by an untrusted CA", func() *x509.Certificate {
+				otherCACert, otherCAKey := newCA("other-ca")
+				return newClientCert(otherCACert, otherCAKey, "front-proxy")
+			}),
+			Entry("with client certificate having a not allowed Common Name", func() *x509.Certificate {
+				return newClientCert(caCert, caKey, "other-proxy")
+			}),
+		)
+	})
+
+	When("client certificate authentication is enabled", func() {
+		var (
+			caCert *x509.Certificate
+			caKey  *ecdsa.PrivateKey
+		)
+
+		BeforeEach(func() {
+			// given
+			caCert, caKey = newCA("client-ca")
+			caFile := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+			Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0o600)).To(Succeed())
+
+			c = mocks.NewMockFakeInterface(ctrl)
+			a, err := namespacelister.NewAuthenticator(context.Background(), namespacelister.AuthenticatorOptions{
+				Client:     c,
+				ClientCert: &namespacelister.ClientCertOptions{ClientCAFile: caFile},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			auth = a
+		})
+
+		newRequest := func(ctx context.Context, clientCert *x509.Certificate) *http.Request {
+			r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{clientCert}}
+			return r
+		}
+
+		It("returns user info from the client certificate subject", func(ctx context.Context) {
+			// given
+			r := newRequest(ctx, newClientCert(caCert, caKey, "my-service", "group1", "group2"))
+
+			// when
+			rs, ok, err := auth.AuthenticateRequest(r)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(rs.User.GetName()).To(Equal("my-service"))
+			Expect(rs.User.GetGroups()).To(ContainElements("group1", "group2"))
+		})
+
+		It("rejects client certificates signed by an untrusted CA", func(ctx context.Context) {
+			// given
+			otherCACert, otherCAKey := newCA("other-ca")
+			r := newRequest(ctx, newClientCert(otherCACert, otherCAKey, "my-service"))
+
+			// when
+			rs, ok, err := auth.AuthenticateRequest(r)
+
+			// then
+			Expect(err).To(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(rs).To(BeNil())
+		})
+	})
+
+	It("rejects header and request header authentication together", func() {
+		// when
+		_, err := namespacelister.NewAuthenticator(context.Background(), namespacelister.AuthenticatorOptions{
+			Client:         mocks.NewMockFakeInterface(ctrl),
+			UsernameHeader: userHeaderKey,
+			RequestHeader:  &namespacelister.RequestHeaderOptions{ClientCAFile: "ca.crt"},
+		})
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("mutually exclusive")))
+	})
+
 	When("Header authentication is disabled", func() {
 		BeforeEach(func() {
 			// given
 			c = mocks.NewMockFakeInterface(ctrl)
-			a, err := namespacelister.NewAuthenticator(namespacelister.AuthenticatorOptions{
+			a, err := namespacelister.NewAuthenticator(context.Background(), namespacelister.AuthenticatorOptions{
 				Client: c,
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -111,3 +270,44 @@ var _ = Describe("Authenticator", func() {
 		})
 	})
 })
+
+// newCA generates a self-signed CA certificate and its key
+func newCA(commonName string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	return newCert(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil, nil)
+}
+
+// newClientCert generates a client certificate signed by the provided CA
+func newClientCert(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string, organizations ...string) *x509.Certificate {
+	c, _ := newCert(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName, Organization: organizations},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+	return c
+}
+
+// newCert generates a certificate from the provided template.
+// If no parent is provided, the certificate is self-signed.
+func newCert(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	GinkgoHelper()
+
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	if parent == nil {
+		parent, parentKey = template, k
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	b, err := x509.CreateCertificate(rand.Reader, template, parent, &k.PublicKey, parentKey)
+	Expect(err).NotTo(HaveOccurred())
+	c, err := x509.ParseCertificate(b)
+	Expect(err).NotTo(HaveOccurred())
+	return c, k
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+const (
+	httpHeaderETag        string = "ETag"
+	httpHeaderIfNoneMatch string = "If-None-Match"
+)
+
+// newETag calculates the ETag of a reply.
+// Replies depend on the cache generation and the namespaces it lists, on the subjects the user is resolved to,
+// on the request's path and query, and on the negotiated content type.
+// As subjects are resolved from username and groups only, the latters are used in place of the former.
+// As the cache generation is local to the process, the fingerprint of the namespaces is included too,
+// so the same generation of different replicas or of a previous process does not match different data.
+// If the cache generation is not known, an empty ETag is returned.
+func newETag(nn *corev1.NamespaceList, ui user.Info, r *http.Request, contentType string) string {
+	if nn.ResourceVersion == "" {
+		return ""
+	}
+
+	gg := slices.Clone(ui.GetGroups())
+	slices.Sort(gg)
+
+	h := sha256.New()
+	for _, v := range []string{nn.ResourceVersion, namespacesFingerprint(nn.Items), ui.GetName(), strings.Join(gg, "\x00"), r.URL.Path, r.URL.RawQuery, contentType} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// matchesETag returns true if the request's If-None-Match header matches the provided ETag.
+// As defined by RFC 9110, weak comparison is used.
+func matchesETag(r *http.Request, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, h := range r.Header.Values(httpHeaderIfNoneMatch) {
+		for v := range strings.SplitSeq(h, ",") {
+			v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+			if v == "*" || v == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeNotModifiedIfMatches sets the ETag header and, if the request's If-None-Match header
+// matches the ETag, replies with NotModified. It returns true if the reply has been written.
+func writeNotModifiedIfMatches(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	w.Header().Set(httpHeaderETag, etag)
+	if !matchesETag(r, etag) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
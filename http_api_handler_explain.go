@@ -0,0 +1,118 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
+	"github.com/konflux-ci/namespace-lister/internal/log"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+const (
+	queryParamUser           string = "user"
+	queryParamGroup          string = "group"
+	queryParamServiceAccount string = "serviceaccount"
+)
+
+var _ http.Handler = &ExplainAccessHandler{}
+
+// ExplainAccessHandler explains why a subject can or can not access a namespace.
+// Callers are required to be allowed to get the request's non-resource URL.
+type ExplainAccessHandler struct {
+	explainer  *AccessExplainer
+	authorizer authorizer.Authorizer
+}
+
+func NewExplainAccessHandler(explainer *AccessExplainer, az authorizer.Authorizer) http.Handler {
+	return &ExplainAccessHandler{
+		explainer:  explainer,
+		authorizer: az,
+	}
+}
+
+func (h *ExplainAccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := log.GetLoggerFromContext(r.Context())
+
+	ud := r.Context().Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
+
+	// authorize the caller
+	a := authorizer.AttributesRecord{User: ud.User, Verb: "get", Path: r.URL.Path}
+	d, reason, err := h.authorizer.Authorize(r.Context(), a)
+	if d != authorizer.DecisionAllow {
+		if err != nil {
+			reason = cmp.Or(reason, err.Error())
+		}
+		status.WriteError(l, w, kerrors.NewForbidden(schema.GroupResource{}, "",
+			fmt.Errorf("user %q cannot get path %q: %s", ud.User.GetName(), a.Path, reason)))
+		return
+	}
+
+	// parse the subject
+	s, gg, err := subjectFromQuery(r)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// explain the access
+	p, _ := r.Context().Value(contextkey.ContextKeyAccessProfile).(string)
+	ctx, span := startSpan(r.Context(), "ExplainAccess")
+	ex, err := h.explainer.Explain(ctx, p, *s, gg, r.PathValue("name"))
+	endSpan(span, err)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// build response
+	b, err := json.Marshal(ex)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	w.Header().Add(constants.HttpContentType, constants.HttpContentTypeApplication)
+	write(l, w, b)
+}
+
+// subjectFromQuery parses the subject from the user, group, or serviceaccount query parameters.
+// Exactly one user, one service account, or one group is required, service accounts are expected
+// in the `namespace:name` form. Along with a user or a service account, the group query parameter
+// can be repeated to provide the groups of the subject.
+func subjectFromQuery(r *http.Request) (*rbacv1.Subject, []string, error) {
+	q := r.URL.Query()
+	ss := []*rbacv1.Subject{}
+	if n := q.Get(queryParamUser); n != "" {
+		ss = append(ss, &rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: n})
+	}
+	if v := q.Get(queryParamServiceAccount); v != "" {
+		ns, n, ok := strings.Cut(v, ":")
+		if !ok || ns == "" || n == "" {
+			return nil, nil, kerrors.NewBadRequest(fmt.Sprintf("invalid service account %q: expected the namespace:name form", v))
+		}
+		ss = append(ss, &rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: ns, Name: n})
+	}
+	gg := slices.DeleteFunc(slices.Clone(q[queryParamGroup]), func(g string) bool { return g == "" })
+
+	switch {
+	case len(ss) == 1:
+		return ss[0], gg, nil
+	case len(ss) == 0 && len(gg) == 1:
+		return &rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: gg[0]}, nil, nil
+	default:
+		return nil, nil, kerrors.NewBadRequest(fmt.Sprintf("exactly one among the %s, %s, and %s query parameters is required, "+
+			"%s can be repeated along with %s or %s to provide the subject's groups",
+			queryParamUser, queryParamGroup, queryParamServiceAccount, queryParamGroup, queryParamUser, queryParamServiceAccount))
+	}
+}
@@ -0,0 +1,154 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("HttpHandlerExplain", func() {
+	var (
+		server  *namespacelister.APIServer
+		allowed bool
+		checked []authorizer.Attributes
+	)
+
+	BeforeEach(func() {
+		allowed = true
+		checked = nil
+
+		ar := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "admin"}}, true, nil
+		})
+		server = namespacelister.NewAPIServer(slog.Default(), ar, NamespaceListerMock{}, nil)
+	})
+
+	enable := func() {
+		cli := fake.NewClientBuilder().WithObjects(
+			&rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "ns-get"},
+				Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"namespaces"}}},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "myns", Name: "ns-get"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "ns-get"},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "mygroup"}},
+			},
+		).Build()
+		e := namespacelister.NewAccessExplainer(cli, cli, resourcecache.AccessProfile{
+			Name:             resourcecache.DefaultAccessProfileName,
+			AccessAttributes: cache.DefaultAccessAttributes,
+		})
+		az := authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+			checked = append(checked, a)
+			if allowed {
+				return authorizer.DecisionAllow, "", nil
+			}
+			return authorizer.DecisionNoOpinion, "not allowed", nil
+		})
+		server.WithAccessExplainer(e, az)
+	}
+
+	get := func(ctx context.Context, path string) *http.Response {
+		GinkgoHelper()
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	It("explains the access of the subject to the namespace", func(ctx context.Context) {
+		// given
+		enable()
+
+		// when
+		rs := get(ctx, "/admin/v1/namespaces/myns/explain?group=mygroup")
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		ex := namespacelister.AccessExplanation{}
+		Expect(json.NewDecoder(rs.Body).Decode(&ex)).To(Succeed())
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Subject).To(Equal(rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "mygroup"}))
+		Expect(ex.Grants).To(HaveExactElements(HaveField("Name", "ns-get")))
+		Expect(checked).To(HaveExactElements(And(
+			HaveField("GetUser().GetName()", "admin"),
+			HaveField("GetVerb()", "get"),
+			HaveField("IsResourceRequest()", false),
+			HaveField("GetPath()", "/admin/v1/namespaces/myns/explain"),
+		)))
+	})
+
+	It("explains the access of the user through the provided groups", func(ctx context.Context) {
+		// given
+		enable()
+
+		// when
+		rs := get(ctx, "/admin/v1/namespaces/myns/explain?user=myuser&group=othergroup&group=mygroup")
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		ex := namespacelister.AccessExplanation{}
+		Expect(json.NewDecoder(rs.Body).Decode(&ex)).To(Succeed())
+		Expect(ex.Allowed).To(BeTrue())
+		Expect(ex.Subject).To(Equal(rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "myuser"}))
+		Expect(ex.Groups).To(Equal([]string{"mygroup", "othergroup", "system:authenticated"}))
+		Expect(ex.Grants).To(HaveExactElements(And(HaveField("Name", "ns-get"), HaveField("Group", "mygroup"))))
+	})
+
+	DescribeTable("replies with a Status on errors", func(ctx context.Context, enabled bool, path string, expectedCode int, expectedReason metav1.StatusReason) {
+		// given
+		if enabled {
+			enable()
+		}
+		allowed = false
+
+		// when
+		rs := get(ctx, path)
+
+		// then
+		Expect(rs.StatusCode).To(Equal(expectedCode))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(rs.Body).Decode(&s)).To(Succeed())
+		Expect(s.Reason).To(Equal(expectedReason))
+	},
+		Entry("admin endpoint disabled", false, "/admin/v1/namespaces/myns/explain?user=myuser",
+			http.StatusNotFound, metav1.StatusReasonNotFound),
+		Entry("caller not allowed", true, "/admin/v1/namespaces/myns/explain?user=myuser",
+			http.StatusForbidden, metav1.StatusReasonForbidden),
+	)
+
+	DescribeTable("rejects invalid subjects", func(ctx context.Context, query string) {
+		// given
+		enable()
+
+		// when
+		rs := get(ctx, "/admin/v1/namespaces/myns/explain?"+query)
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusBadRequest))
+	},
+		Entry("no subject", ""),
+		Entry("multiple subjects", "user=myuser&serviceaccount=myns:mysa"),
+		Entry("multiple groups without user nor service account", "group=mygroup&group=othergroup"),
+		Entry("service account without namespace", "serviceaccount=mysa"),
+	)
+})
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
+	"github.com/konflux-ci/namespace-lister/internal/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+)
+
+var _ http.Handler = &GetNamespaceHandler{}
+
+type GetNamespaceHandler struct {
+	lister NamespaceLister
+}
+
+func NewGetNamespaceHandler(lister NamespaceLister) http.Handler {
+	return &GetNamespaceHandler{
+		lister: lister,
+	}
+}
+
+func (h *GetNamespaceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := log.GetLoggerFromContext(r.Context())
+
+	ud := r.Context().Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
+
+	// negotiate the reply format
+	rf, err := negotiateResponseFormat(r, false)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// retrieve the namespace as the user
+	audit.RecordVerb(r.Context(), "get")
+	ctx, span := startSpan(r.Context(), "GetNamespace")
+	ns, generation, err := h.lister.GetNamespace(ctx, ud.User.GetName(), ud.User.GetGroups(), r.PathValue("name"))
+	endSpan(span, err)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+	audit.RecordNamespaces(r.Context(), generation, []corev1.Namespace{*ns})
+
+	// build response
+	b, err := h.serialize(r.Context(), ns, rf)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	w.Header().Add(constants.HttpContentType, rf.ContentType(false))
+	addStaleWarning(w, r, h.lister)
+	write(l, w, b)
+}
+
+// serialize encodes the namespace in the negotiated media type, converting it to a Table if requested
+func (h *GetNamespaceHandler) serialize(ctx context.Context, ns *corev1.Namespace, rf *responseFormat) (b []byte, err error) {
+	_, span := startSpan(ctx, "Serialize", trace.WithAttributes(attribute.String(attributeContentType, rf.ContentType(false))))
+	defer func() { endSpan(span, err) }()
+
+	var o runtime.Object = ns
+	if rf.tableOptions != nil {
+		if o, err = newNamespaceTable([]corev1.Namespace{*ns}, rf.tableOptions); err != nil {
+			return nil, err
+		}
+	}
+	return rf.Encode(o)
+}
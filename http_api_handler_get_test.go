@@ -0,0 +1,172 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+var _ = Describe("HttpHandlerGet", func() {
+	var request *http.Request
+
+	BeforeEach(func(tctx context.Context) {
+		var err error
+		ctx := context.WithValue(tctx, contextkey.ContextKeyUserDetails,
+			&authenticator.Response{
+				User: &user.DefaultInfo{
+					Name:   "myuser",
+					Groups: []string{"mygroup"},
+				},
+			})
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/namespaces/myns", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.SetPathValue("name", "myns")
+	})
+
+	It("retrieves the namespace", func() {
+		// given
+		expected := corev1.Namespace{
+			TypeMeta: metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "myns",
+				Labels: map[string]string{"virtual.konflux-ci.dev/access": "user"},
+			},
+		}
+		lister := NamespaceListerMock{GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+			Expect(username).To(Equal("myuser"))
+			Expect(groups).To(Equal([]string{"mygroup"}))
+			Expect(name).To(Equal("myns"))
+			return &expected, "1", nil
+		}}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
+		ns := corev1.Namespace{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&ns)).To(Succeed())
+		Expect(ns).To(Equal(expected))
+	})
+
+	It("records the namespace and the cache generation in the audit event", func() {
+		// given
+		lister := NamespaceListerMock{GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}, "7", nil
+		}}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+		ev := &audit.Event{Level: audit.LevelNamespaces}
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request.WithContext(audit.WithEvent(request.Context(), ev)))
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(ev.Verb).To(Equal("get"))
+		Expect(ev.CacheGeneration).To(Equal("7"))
+		Expect(ev.Namespaces).To(HaveExactElements("myns"))
+	})
+
+	It("warns the client when data might be outdated", func() {
+		// given
+		lister := NamespaceListerMock{
+			GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+				return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}, "1", nil
+			},
+			StaleFunc: func() bool { return true },
+		}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(w.Result().Header.Get(constants.HttpWarning)).To(Equal(constants.HttpWarningStaleData))
+	})
+
+	It("retrieves the namespace as a Table", func() {
+		// given
+		lister := NamespaceListerMock{GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}}, "1", nil
+		}}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+		request.Header.Set("Accept", "application/json;as=Table;g=meta.k8s.io;v=v1")
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		t := metav1.Table{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&t)).To(Succeed())
+		Expect(t.Kind).To(Equal("Table"))
+		Expect(t.ColumnDefinitions).NotTo(BeEmpty())
+		Expect(t.Rows).To(HaveLen(1))
+		Expect(t.Rows[0].Cells[0]).To(Equal("myns"))
+	})
+
+	It("returns a NotFound Status when the namespace is not accessible", func() {
+		// given
+		lister := NamespaceListerMock{GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+			return nil, "", kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+		}}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Code).To(BeEquivalentTo(http.StatusNotFound))
+		Expect(s.Reason).To(Equal(metav1.StatusReasonNotFound))
+		Expect(s.Details.Name).To(Equal("myns"))
+	})
+
+	It("returns an error when lister returns an error", func() {
+		// given
+		lister := NamespaceListerMock{GetNamespaceFunc: func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+			return nil, "", errors.New("unhandled error")
+		}}
+		handler := namespacelister.NewGetNamespaceHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+})
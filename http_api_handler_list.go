@@ -1,15 +1,27 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/konflux-ci/namespace-lister/internal/audit"
 	"github.com/konflux-ci/namespace-lister/internal/constants"
 	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
 	"github.com/konflux-ci/namespace-lister/internal/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metainternalversionscheme "k8s.io/apimachinery/pkg/apis/meta/internalversion/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 )
 
@@ -26,37 +38,202 @@ func NewListNamespacesHandler(lister NamespaceLister) http.Handler {
 }
 
 func (h *ListNamespacesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	l := log.GetLoggerFromContext(ctx)
+	l := log.GetLoggerFromContext(r.Context())
 
 	ud := r.Context().Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
 
+	// parse query parameters
+	opts := metainternalversion.ListOptions{}
+	if err := metainternalversionscheme.ParameterCodec.DecodeParameters(r.URL.Query(), metav1.SchemeGroupVersion, &opts); err != nil {
+		status.Write(l, w, kerrors.NewBadRequest(err.Error()).Status())
+		return
+	}
+
+	// build the filter from label and field selectors
+	f, err := newNamespaceFilter(opts.LabelSelector, opts.FieldSelector)
+	if err != nil {
+		status.Write(l, w, kerrors.NewBadRequest(err.Error()).Status())
+		return
+	}
+
+	// negotiate the reply format
+	rf, err := negotiateResponseFormat(r, opts.Watch)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// watch namespaces if requested
+	if opts.Watch {
+		audit.RecordVerb(r.Context(), "watch")
+		h.serveWatch(l, w, r, ud, f, rf, &opts)
+		return
+	}
+
 	// retrieve projects as the user
-	nn, err := h.lister.ListNamespaces(r.Context(), ud.User.GetName(), ud.User.GetGroups())
+	audit.RecordVerb(r.Context(), "list")
+	ctx, span := startSpan(r.Context(), "ListNamespaces")
+	nn, err := h.lister.ListNamespaces(ctx, ud.User.GetName(), ud.User.GetGroups())
+	if err == nil {
+		span.SetAttributes(attribute.Int(attributeNamespaces, len(nn.Items)))
+	}
+	endSpan(span, err)
 	if err != nil {
-		serr := &kerrors.StatusError{}
-		if errors.As(err, &serr) {
-			http.Error(w, serr.Error(), int(serr.Status().Code))
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// reply with NotModified if the client already has the current data.
+	// Tables are excluded as their Age column changes over time.
+	if rf.tableOptions == nil {
+		etag := newETag(nn, ud.User, r, rf.ContentType(false))
+		if writeNotModifiedIfMatches(w, r, etag) {
 			return
 		}
+	}
+
+	// apply label and field selectors
+	nn.Items = f.Filter(nn.Items)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// apply limit and continue
+	if err := paginate(nn, opts.Limit, opts.Continue); err != nil {
+		status.WriteError(l, w, err)
 		return
 	}
+	audit.RecordNamespaces(r.Context(), nn.ResourceVersion, nn.Items)
 
 	// build response
-	// for PoC limited to JSON
-	b, err := json.Marshal(nn)
+	b, err := h.serialize(r.Context(), nn, rf)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	w.Header().Add(constants.HttpContentType, rf.ContentType(false))
+	addStaleWarning(w, r, h.lister)
+	write(l, w, b)
+}
+
+// serialize encodes the namespaces in the negotiated media type, converting them to a Table if requested
+func (h *ListNamespacesHandler) serialize(ctx context.Context, nn *corev1.NamespaceList, rf *responseFormat) (b []byte, err error) {
+	_, span := startSpan(ctx, "Serialize", trace.WithAttributes(attribute.String(attributeContentType, rf.ContentType(false))))
+	defer func() { endSpan(span, err) }()
+
+	var o runtime.Object = nn
+	if rf.tableOptions != nil {
+		t, err := newNamespaceTable(nn.Items, rf.tableOptions)
+		if err != nil {
+			return nil, err
+		}
+		t.ListMeta = nn.ListMeta
+		o = t
+	}
+	return rf.Encode(o)
+}
+
+// serveWatch streams the changes on the namespaces the user has access to
+// as a sequence of WatchEvents encoded in the negotiated media type.
+// If the client requested Tables, event objects are converted to Tables:
+// column definitions are included in the first event only.
+func (h *ListNamespacesHandler) serveWatch(l *slog.Logger, w http.ResponseWriter, r *http.Request, ud *authenticator.Response, f *namespaceFilter, rf *responseFormat, opts *metainternalversion.ListOptions) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		status.WriteError(l, w, errors.New("streaming is not supported"))
+		return
+	}
+
+	// honor the requested timeout
+	ctx := r.Context()
+	if opts.TimeoutSeconds != nil && *opts.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*opts.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	// start watching namespaces as the user
+	sctx, span := startSpan(ctx, "WatchNamespaces")
+	wi, err := h.lister.WatchNamespaces(sctx, ud.User.GetName(), ud.User.GetGroups())
+	endSpan(span, err)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+	wi = f.FilterWatch(wi)
+	defer wi.Stop()
+
+	w.Header().Add(constants.HttpContentType, rf.ContentType(true))
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	to := rf.tableOptions
+	enc := rf.NewStreamEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-wi.ResultChan():
+			if !ok {
+				return
+			}
+
+			o := e.Object
+			if ns, ok := e.Object.(*corev1.Namespace); ok && to != nil {
+				t, err := newNamespaceTable([]corev1.Namespace{*ns}, to)
+				if err != nil {
+					l.Error("error converting watch event object to table", "error", err)
+					h.writeWatchError(l, enc, rf, err)
+					return
+				}
+				o = t
+				to = &metav1.TableOptions{NoHeaders: true, IncludeObject: to.IncludeObject}
+			}
+
+			b, err := rf.Encode(o)
+			if err != nil {
+				l.Error("error encoding watch event object", "error", err)
+				h.writeWatchError(l, enc, rf, err)
+				return
+			}
+
+			we := metav1.WatchEvent{Type: string(e.Type), Object: runtime.RawExtension{Raw: b}}
+			if err := enc.Encode(&we); err != nil {
+				l.Error("error writing watch event", "error", err)
+				return
+			}
+			flusher.Flush()
+
+			// record the namespaces sent to the client
+			if ns, ok := e.Object.(*corev1.Namespace); ok && (e.Type == watch.Added || e.Type == watch.Modified) {
+				audit.RecordWatchedNamespace(r.Context(), ns.GetName())
+			}
+		}
+	}
+}
+
+// writeWatchError notifies the client about an error via an ERROR WatchEvent embedding a Status,
+// as the HTTP status code has already been written
+func (h *ListNamespacesHandler) writeWatchError(l *slog.Logger, enc streaming.Encoder, rf *responseFormat, err error) {
+	s := status.FromError(err)
+	b, err := rf.Encode(&s)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		l.Error("error encoding watch error status", "error", err)
 		return
 	}
 
-	w.Header().Add(constants.HttpContentType, constants.HttpContentTypeApplication)
-	h.write(l, w, b)
+	we := metav1.WatchEvent{Type: string(watch.Error), Object: runtime.RawExtension{Raw: b}}
+	if err := enc.Encode(&we); err != nil {
+		l.Error("error writing watch error event", "error", err)
+	}
+}
+
+// addStaleWarning warns the client via a Warning header if the reply is built from data that might be outdated
+func addStaleWarning(w http.ResponseWriter, r *http.Request, lister NamespaceLister) {
+	if lister.Stale(r.Context()) {
+		w.Header().Add(constants.HttpWarning, constants.HttpWarningStaleData)
+	}
 }
 
-func (h *ListNamespacesHandler) write(l *slog.Logger, w http.ResponseWriter, data []byte) bool {
+func write(l *slog.Logger, w http.ResponseWriter, data []byte) bool {
 	if _, err := w.Write(data); err != nil {
 		l.Error("error writing reply", "error", err)
 		w.WriteHeader(http.StatusInternalServerError)
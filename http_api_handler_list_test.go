@@ -7,25 +7,61 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/audit"
 	"github.com/konflux-ci/namespace-lister/internal/constants"
 	"github.com/konflux-ci/namespace-lister/internal/contextkey"
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 )
 
-type NamespaceListerMock func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error)
+type NamespaceListerMock struct {
+	ListNamespacesFunc  func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error)
+	WatchNamespacesFunc func(ctx context.Context, username string, groups []string) (watch.Interface, error)
+	GetNamespaceFunc    func(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error)
+	StaleFunc           func() bool
+
+	GetNamespaceSubjectsFunc func(ctx context.Context, username string, groups []string, name string) (*namespacelister.NamespaceSubjects, error)
+}
 
 func (m NamespaceListerMock) ListNamespaces(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
-	return m(ctx, username, groups)
+	return m.ListNamespacesFunc(ctx, username, groups)
+}
+
+func (m NamespaceListerMock) WatchNamespaces(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+	return m.WatchNamespacesFunc(ctx, username, groups)
+}
+
+func (m NamespaceListerMock) GetNamespace(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+	return m.GetNamespaceFunc(ctx, username, groups, name)
+}
+
+func (m NamespaceListerMock) GetNamespaceSubjects(ctx context.Context, username string, groups []string, name string) (*namespacelister.NamespaceSubjects, error) {
+	return m.GetNamespaceSubjectsFunc(ctx, username, groups, name)
+}
+
+func (m NamespaceListerMock) Stale(_ context.Context) bool {
+	return m.StaleFunc != nil && m.StaleFunc()
+}
+
+// withSpecContext moves the request's values to the spec context,
+// as the one used in the BeforeEach node is cancelled when the spec runs
+func withSpecContext(ctx context.Context, r *http.Request) *http.Request {
+	ud := r.Context().Value(contextkey.ContextKeyUserDetails)
+	return r.WithContext(context.WithValue(ctx, contextkey.ContextKeyUserDetails, ud))
 }
 
 var _ = Describe("HttpHandlerList", func() {
@@ -49,9 +85,9 @@ var _ = Describe("HttpHandlerList", func() {
 		if err != nil {
 			panic(err)
 		}
-		lister := NamespaceListerMock(func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+		lister := NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
 			return &expected, nil
-		})
+		}}
 		handler := namespacelister.NewListNamespacesHandler(lister)
 
 		w := httptest.NewRecorder()
@@ -63,10 +99,13 @@ var _ = Describe("HttpHandlerList", func() {
 		Expect(w.Result()).NotTo(BeNil())
 		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
 		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
-		Expect(io.ReadAll(w.Result().Body)).To(Equal(eb))
+		Expect(io.ReadAll(w.Result().Body)).To(MatchJSON(eb))
 	},
-		Entry("empty list", corev1.NamespaceList{}),
+		Entry("empty list", corev1.NamespaceList{
+			TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
+		}),
 		Entry("non empty list", corev1.NamespaceList{
+			TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
 			Items: []corev1.Namespace{
 				{
 					ObjectMeta: metav1.ObjectMeta{
@@ -77,11 +116,11 @@ var _ = Describe("HttpHandlerList", func() {
 		}),
 	)
 
-	DescribeTable("returns an error when lister returns an error", func(expectedErr error, expectedResponseStatus int) {
+	DescribeTable("returns an error Status when lister returns an error", func(expectedErr error, expectedResponseStatus int, expectedReason metav1.StatusReason) {
 		// given
-		lister := NamespaceListerMock(func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+		lister := NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
 			return nil, expectedErr
-		})
+		}}
 		handler := namespacelister.NewListNamespacesHandler(lister)
 
 		w := httptest.NewRecorder()
@@ -92,10 +131,750 @@ var _ = Describe("HttpHandlerList", func() {
 		// then
 		Expect(w.Result()).NotTo(BeNil())
 		Expect(w.Result().StatusCode).To(Equal(expectedResponseStatus))
-		// Expect(w.Result().Header.Get(HttpContentType)).To(Equal(HttpContentTypeApplication))
-		Expect(io.ReadAll(w.Result().Body)).To(BeEquivalentTo(expectedErr.Error() + "\n"))
+		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Code).To(BeEquivalentTo(expectedResponseStatus))
+		Expect(s.Reason).To(Equal(expectedReason))
+		Expect(s.Message).To(ContainSubstring(expectedErr.Error()))
 	},
-		Entry("unhandled error", errors.New("unhandled error"), http.StatusInternalServerError),
-		Entry("handled error", kerrors.NewTimeoutError("timed-out", 200), http.StatusGatewayTimeout),
+		Entry("unhandled error", errors.New("unhandled error"), http.StatusInternalServerError, metav1.StatusReasonInternalError),
+		Entry("handled error", kerrors.NewTimeoutError("timed-out", 200), http.StatusGatewayTimeout, metav1.StatusReasonTimeout),
 	)
+
+	It("streams watch events when watch is requested", func(ctx context.Context) {
+		// given
+		fw := watch.NewFake()
+		lister := NamespaceListerMock{WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+			return fw, nil
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		request = withSpecContext(ctx, request)
+		request.URL.RawQuery = "watch=true"
+
+		w := httptest.NewRecorder()
+		go func() {
+			defer GinkgoRecover()
+			defer fw.Stop()
+
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}})
+			fw.Delete(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}})
+		}()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
+
+		d := json.NewDecoder(w.Result().Body)
+		ee := []string{}
+		for d.More() {
+			e := metav1.WatchEvent{}
+			Expect(d.Decode(&e)).To(Succeed())
+
+			ns := corev1.Namespace{}
+			Expect(json.Unmarshal(e.Object.Raw, &ns)).To(Succeed())
+			ee = append(ee, e.Type+"/"+ns.Name)
+		}
+		Expect(ee).To(Equal([]string{"ADDED/myns", "DELETED/myns"}))
+	})
+
+	It("records the watched namespaces in the audit event", func(ctx context.Context) {
+		// given
+		fw := watch.NewFake()
+		lister := NamespaceListerMock{WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+			return fw, nil
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		ev := &audit.Event{Level: audit.LevelNamespaces}
+		request = withSpecContext(audit.WithEvent(ctx, ev), request)
+		request.URL.RawQuery = "watch=true"
+
+		w := httptest.NewRecorder()
+		go func() {
+			defer GinkgoRecover()
+			defer fw.Stop()
+
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}})
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "otherns"}})
+			fw.Modify(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}})
+			fw.Delete(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "otherns"}})
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "newns"}})
+		}()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(ev.Verb).To(Equal("watch"))
+		Expect(ev.NamespaceCount).To(HaveValue(Equal(3)))
+		Expect(ev.Namespaces).To(HaveExactElements("myns", "otherns", "newns"))
+	})
+
+	It("returns an error when watch is not supported", func(ctx context.Context) {
+		// given
+		lister := NamespaceListerMock{WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+			return nil, kerrors.NewMethodNotSupported(corev1.Resource("namespaces"), "watch")
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		request = withSpecContext(ctx, request)
+		request.URL.RawQuery = "watch=true"
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	DescribeTable("warns the client when data might be outdated", func(stale bool, expectedWarning string) {
+		// given
+		lister := NamespaceListerMock{
+			ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				return &corev1.NamespaceList{}, nil
+			},
+			StaleFunc: func() bool { return stale },
+		}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(w.Result().Header.Get(constants.HttpWarning)).To(Equal(expectedWarning))
+	},
+		Entry("stale data", true, constants.HttpWarningStaleData),
+		Entry("fresh data", false, ""),
+	)
+
+	DescribeTable("filters namespaces by selectors", func(query string, expectedNames ...string) {
+		// given
+		lister := NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+			return &corev1.NamespaceList{
+				Items: []corev1.Namespace{
+					{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: map[string]string{"konflux-ci.dev/type": "tenant"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "user", Labels: map[string]string{"konflux-ci.dev/type": "user"}}},
+				},
+			}, nil
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		request.URL.RawQuery = query
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		nn := corev1.NamespaceList{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&nn)).To(Succeed())
+		names := []string{}
+		for _, ns := range nn.Items {
+			names = append(names, ns.Name)
+		}
+		Expect(names).To(ConsistOf(expectedNames))
+	},
+		Entry("no selectors", "", "tenant", "user"),
+		Entry("label selector", "labelSelector=konflux-ci.dev/type%3Dtenant", "tenant"),
+		Entry("label selector not matching", "labelSelector=konflux-ci.dev/type%3Dother"),
+		Entry("field selector", "fieldSelector=metadata.name%3Duser", "user"),
+		Entry("label and field selectors", "labelSelector=konflux-ci.dev/type&fieldSelector=metadata.name!%3Duser", "tenant"),
+	)
+
+	DescribeTable("returns a BadRequest Status for invalid selectors", func(query string) {
+		// given
+		lister := NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+			return &corev1.NamespaceList{}, nil
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		request.URL.RawQuery = query
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Code).To(BeEquivalentTo(http.StatusBadRequest))
+		Expect(s.Reason).To(Equal(metav1.StatusReasonBadRequest))
+	},
+		Entry("invalid label selector", "labelSelector=%3D%3Dinvalid"),
+		Entry("invalid field selector", "fieldSelector=metadata.name"),
+		Entry("unsupported field selector", "fieldSelector=status.phase%3DActive"),
+	)
+
+	Describe("paginates namespaces", func() {
+		var handler http.Handler
+		var resourceVersion string
+		var names []string
+
+		BeforeEach(func() {
+			resourceVersion = "3"
+			names = []string{"myns-1", "myns-2", "myns-3", "myns-4", "myns-5"}
+			lister := NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				nn := &corev1.NamespaceList{ListMeta: metav1.ListMeta{ResourceVersion: resourceVersion}}
+				for _, n := range names {
+					nn.Items = append(nn.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: n}})
+				}
+				return nn, nil
+			}}
+			handler = namespacelister.NewListNamespacesHandler(lister)
+		})
+
+		list := func(query string) (*http.Response, *corev1.NamespaceList) {
+			r := request.Clone(request.Context())
+			r.URL.RawQuery = query
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			nn := corev1.NamespaceList{}
+			if w.Result().StatusCode == http.StatusOK {
+				Expect(json.NewDecoder(w.Result().Body).Decode(&nn)).To(Succeed())
+			}
+			return w.Result(), &nn
+		}
+
+		It("walks all the pages with continue tokens", func() {
+			// when
+			names := []string{}
+			remaining := []int64{}
+			query := "limit=2"
+			for {
+				rs, nn := list(query)
+				Expect(rs.StatusCode).To(Equal(http.StatusOK))
+				Expect(nn.ResourceVersion).To(Equal("3"))
+				for _, ns := range nn.Items {
+					names = append(names, ns.Name)
+				}
+				if nn.Continue == "" {
+					Expect(nn.RemainingItemCount).To(BeNil())
+					break
+				}
+				remaining = append(remaining, *nn.RemainingItemCount)
+				query = "limit=2&continue=" + nn.Continue
+			}
+
+			// then
+			Expect(names).To(Equal([]string{"myns-1", "myns-2", "myns-3", "myns-4", "myns-5"}))
+			Expect(remaining).To(Equal([]int64{3, 1}))
+		})
+
+		It("returns all the namespaces when limit is not set", func() {
+			// when
+			rs, nn := list("")
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusOK))
+			Expect(nn.Items).To(HaveLen(5))
+			Expect(nn.Continue).To(BeEmpty())
+		})
+
+		It("returns a Gone Status when cache data has been replaced", func() {
+			// given
+			_, nn := list("limit=2")
+			Expect(nn.Continue).NotTo(BeEmpty())
+			resourceVersion = "4"
+
+			// when
+			rs, _ := list("limit=2&continue=" + nn.Continue)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusGone))
+			s := metav1.Status{}
+			Expect(json.NewDecoder(rs.Body).Decode(&s)).To(Succeed())
+			Expect(s.Kind).To(Equal("Status"))
+			Expect(s.Code).To(BeEquivalentTo(http.StatusGone))
+			Expect(s.Reason).To(Equal(metav1.StatusReasonExpired))
+		})
+
+		It("returns a Gone Status when data differ with the same resourceVersion", func() {
+			// given
+			_, nn := list("limit=2")
+			Expect(nn.Continue).NotTo(BeEmpty())
+			names = []string{"myns-1", "myns-2", "myns-3", "other-4", "other-5"}
+
+			// when
+			rs, _ := list("limit=2&continue=" + nn.Continue)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusGone))
+		})
+
+		It("returns a BadRequest Status for an invalid continue token", func() {
+			// when
+			rs, _ := list("limit=2&continue=invalid")
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusBadRequest))
+			s := metav1.Status{}
+			Expect(json.NewDecoder(rs.Body).Decode(&s)).To(Succeed())
+			Expect(s.Reason).To(Equal(metav1.StatusReasonBadRequest))
+		})
+	})
+
+	Describe("converts namespaces to Table", func() {
+		const tableAccept = "application/json;as=Table;g=meta.k8s.io;v=v1"
+
+		var handler http.Handler
+		var fw *watch.FakeWatcher
+
+		BeforeEach(func() {
+			fw = watch.NewFake()
+			lister := NamespaceListerMock{
+				ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+					return &corev1.NamespaceList{
+						ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+						Items: []corev1.Namespace{
+							{
+								ObjectMeta: metav1.ObjectMeta{
+									Name: "myns",
+									Labels: map[string]string{
+										"virtual.konflux-ci.dev/access":     "user",
+										"virtual.konflux-ci.dev/visibility": "private",
+									},
+									CreationTimestamp: metav1.NewTime(time.Now().Add(-72 * time.Hour)),
+								},
+								Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+							},
+						},
+					}, nil
+				},
+				WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+					return fw, nil
+				},
+			}
+			handler = namespacelister.NewListNamespacesHandler(lister)
+		})
+
+		It("returns a Table", func() {
+			// given
+			request.Header.Set("Accept", tableAccept)
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+			t := metav1.Table{}
+			Expect(json.NewDecoder(w.Result().Body).Decode(&t)).To(Succeed())
+			Expect(t.Kind).To(Equal("Table"))
+			Expect(t.APIVersion).To(Equal("meta.k8s.io/v1"))
+			Expect(t.ResourceVersion).To(Equal("1"))
+
+			columns := []string{}
+			for _, c := range t.ColumnDefinitions {
+				columns = append(columns, c.Name)
+			}
+			Expect(columns).To(Equal([]string{"Name", "Status", "Age", "Access", "Visibility"}))
+			Expect(t.Rows).To(HaveLen(1))
+			Expect(t.Rows[0].Cells).To(Equal([]any{"myns", "Active", "3d", "user", "private"}))
+
+			o := metav1.PartialObjectMetadata{}
+			Expect(json.Unmarshal(t.Rows[0].Object.Raw, &o)).To(Succeed())
+			Expect(o.Kind).To(Equal("PartialObjectMetadata"))
+			Expect(o.Name).To(Equal("myns"))
+		})
+
+		DescribeTable("embeds objects as requested", func(query string, expectedKind string) {
+			// given
+			request.Header.Set("Accept", tableAccept)
+			request.URL.RawQuery = query
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+			t := metav1.Table{}
+			Expect(json.NewDecoder(w.Result().Body).Decode(&t)).To(Succeed())
+			Expect(t.Rows).To(HaveLen(1))
+			if expectedKind == "" {
+				Expect(t.Rows[0].Object.Raw).To(BeEmpty())
+				return
+			}
+			tm := metav1.TypeMeta{}
+			Expect(json.Unmarshal(t.Rows[0].Object.Raw, &tm)).To(Succeed())
+			Expect(tm.Kind).To(Equal(expectedKind))
+		},
+			Entry("default", "", "PartialObjectMetadata"),
+			Entry("metadata", "includeObject=Metadata", "PartialObjectMetadata"),
+			Entry("object", "includeObject=Object", "Namespace"),
+			Entry("none", "includeObject=None", ""),
+		)
+
+		It("returns a BadRequest Status for invalid includeObject", func() {
+			// given
+			request.Header.Set("Accept", tableAccept)
+			request.URL.RawQuery = "includeObject=Invalid"
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		DescribeTable("returns a NotAcceptable Status for unsupported media types", func(accept string) {
+			// given
+			request.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusNotAcceptable))
+			s := metav1.Status{}
+			Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+			Expect(s.Kind).To(Equal("Status"))
+			Expect(s.Reason).To(Equal(metav1.StatusReasonNotAcceptable))
+		},
+			Entry("unsupported conversion", "application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1"),
+			Entry("unsupported table version", "application/json;as=Table;g=meta.k8s.io;v=v1beta1"),
+			Entry("unsupported media type", "text/html"),
+		)
+
+		It("streams Table watch events", func(ctx context.Context) {
+			// given
+			request = withSpecContext(ctx, request)
+			request.Header.Set("Accept", tableAccept)
+			request.URL.RawQuery = "watch=true"
+			w := httptest.NewRecorder()
+			go func() {
+				defer GinkgoRecover()
+				defer fw.Stop()
+
+				fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-1"}})
+				fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-2"}})
+			}()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+			d := json.NewDecoder(w.Result().Body)
+			tt := []metav1.Table{}
+			for d.More() {
+				e := metav1.WatchEvent{}
+				Expect(d.Decode(&e)).To(Succeed())
+
+				t := metav1.Table{}
+				Expect(json.Unmarshal(e.Object.Raw, &t)).To(Succeed())
+				tt = append(tt, t)
+			}
+			Expect(tt).To(HaveLen(2))
+			Expect(tt[0].ColumnDefinitions).NotTo(BeEmpty())
+			Expect(tt[0].Rows[0].Cells[0]).To(Equal("myns-1"))
+			Expect(tt[1].ColumnDefinitions).To(BeEmpty())
+			Expect(tt[1].Rows[0].Cells[0]).To(Equal("myns-2"))
+		})
+	})
+
+	Describe("negotiates the response encoding", func() {
+		var handler http.Handler
+		var fw *watch.FakeWatcher
+
+		BeforeEach(func() {
+			fw = watch.NewFake()
+			lister := NamespaceListerMock{
+				ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+					return &corev1.NamespaceList{
+						TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
+						Items:    []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myns"}}},
+					}, nil
+				},
+				WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+					return fw, nil
+				},
+			}
+			handler = namespacelister.NewListNamespacesHandler(lister)
+		})
+
+		DescribeTable("encodes the namespace list", func(accept, expectedContentType string) {
+			// given
+			request.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(expectedContentType))
+
+			b, err := io.ReadAll(w.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+			o, _, err := clientgoscheme.Codecs.UniversalDeserializer().Decode(b, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(o).To(BeAssignableToTypeOf(&corev1.NamespaceList{}))
+			Expect(o.(*corev1.NamespaceList).Items).To(HaveLen(1))
+			Expect(o.(*corev1.NamespaceList).Items[0].Name).To(Equal("myns"))
+		},
+			Entry("json", "application/json", constants.HttpContentTypeApplication),
+			Entry("yaml", "application/yaml", "application/yaml"),
+			Entry("protobuf", "application/vnd.kubernetes.protobuf", "application/vnd.kubernetes.protobuf"),
+			Entry("preferred protobuf", "application/vnd.kubernetes.protobuf,application/json", "application/vnd.kubernetes.protobuf"),
+		)
+
+		DescribeTable("returns a NotAcceptable Status for unsupported media types", func(query, accept string) {
+			// given
+			request.URL.RawQuery = query
+			request.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusNotAcceptable))
+			s := metav1.Status{}
+			Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+			Expect(s.Reason).To(Equal(metav1.StatusReasonNotAcceptable))
+		},
+			Entry("xml", "", "application/xml"),
+			Entry("protobuf table", "", "application/vnd.kubernetes.protobuf;as=Table;g=meta.k8s.io;v=v1"),
+			Entry("yaml watch", "watch=true", "application/yaml"),
+		)
+
+		It("streams protobuf watch events", func(ctx context.Context) {
+			// given
+			request = withSpecContext(ctx, request)
+			request.URL.RawQuery = "watch=true"
+			request.Header.Set("Accept", "application/vnd.kubernetes.protobuf")
+			w := httptest.NewRecorder()
+			go func() {
+				defer GinkgoRecover()
+				defer fw.Stop()
+
+				fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns"}})
+			}()
+
+			// when
+			handler.ServeHTTP(w, request)
+
+			// then
+			Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(w.Result().Header.Get(constants.HttpContentType)).
+				To(Equal("application/vnd.kubernetes.protobuf;stream=watch"))
+
+			info, ok := runtime.SerializerInfoForMediaType(clientgoscheme.Codecs.SupportedMediaTypes(), "application/vnd.kubernetes.protobuf")
+			Expect(ok).To(BeTrue())
+			fr := info.StreamSerializer.NewFrameReader(w.Result().Body)
+			d := streaming.NewDecoder(fr, info.StreamSerializer.Serializer)
+			o, _, err := d.Decode(nil, &metav1.WatchEvent{})
+			Expect(err).NotTo(HaveOccurred())
+			e := o.(*metav1.WatchEvent)
+			Expect(e.Type).To(Equal(string(watch.Added)))
+
+			ns, _, err := clientgoscheme.Codecs.UniversalDeserializer().Decode(e.Object.Raw, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ns.(*corev1.Namespace).Name).To(Equal("myns"))
+		})
+	})
+
+	Describe("supports conditional requests", func() {
+		var handler http.Handler
+		var resourceVersion string
+		var items []corev1.Namespace
+
+		newHandler := func() http.Handler {
+			return namespacelister.NewListNamespacesHandler(NamespaceListerMock{ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				return &corev1.NamespaceList{
+					ListMeta: metav1.ListMeta{ResourceVersion: resourceVersion},
+					Items:    items,
+				}, nil
+			}})
+		}
+
+		BeforeEach(func() {
+			resourceVersion = "1"
+			items = []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myns"}}}
+			handler = newHandler()
+		})
+
+		serve := func(r *http.Request) *http.Response {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w.Result()
+		}
+
+		withUser := func(r *http.Request, name string, groups ...string) *http.Request {
+			return r.WithContext(context.WithValue(r.Context(), contextkey.ContextKeyUserDetails,
+				&authenticator.Response{User: &user.DefaultInfo{Name: name, Groups: groups}}))
+		}
+
+		It("replies with NotModified when the ETag matches", func() {
+			// given
+			etag := serve(request).Header.Get("ETag")
+			Expect(etag).NotTo(BeEmpty())
+			request.Header.Set("If-None-Match", etag)
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusNotModified))
+			Expect(rs.Header.Get("ETag")).To(Equal(etag))
+			Expect(io.ReadAll(rs.Body)).To(BeEmpty())
+		})
+
+		DescribeTable("matches If-None-Match values", func(ifNoneMatch func(etag string) string, expectedStatus int) {
+			// given
+			etag := serve(request).Header.Get("ETag")
+			request.Header.Set("If-None-Match", ifNoneMatch(etag))
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(expectedStatus))
+		},
+			Entry("weak ETag", func(etag string) string { return "W/" + etag }, http.StatusNotModified),
+			Entry("list of ETags", func(etag string) string { return `"other", ` + etag }, http.StatusNotModified),
+			Entry("any", func(string) string { return "*" }, http.StatusNotModified),
+			Entry("other ETag", func(string) string { return `"other"` }, http.StatusOK),
+		)
+
+		It("changes the ETag when the cache is restocked", func() {
+			// given
+			etag := serve(request).Header.Get("ETag")
+			resourceVersion = "2"
+			request.Header.Set("If-None-Match", etag)
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusOK))
+			Expect(rs.Header.Get("ETag")).NotTo(Equal(etag))
+		})
+
+		It("changes the ETag when another cache has the same generation but different data", func() {
+			// given
+			etag := serve(request).Header.Get("ETag")
+			items = []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myns", Labels: map[string]string{"key": "value"}}}}
+			handler = newHandler()
+			request.Header.Set("If-None-Match", etag)
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusOK))
+			Expect(rs.Header.Get("ETag")).NotTo(Equal(etag))
+		})
+
+		It("calculates ETags per user", func() {
+			// when
+			etag := serve(withUser(request, "myuser", "group-a", "group-b")).Header.Get("ETag")
+
+			// then
+			Expect(serve(withUser(request, "myuser", "group-b", "group-a")).Header.Get("ETag")).To(Equal(etag))
+			Expect(serve(withUser(request, "myuser", "group-a")).Header.Get("ETag")).NotTo(Equal(etag))
+			Expect(serve(withUser(request, "otheruser", "group-a", "group-b")).Header.Get("ETag")).NotTo(Equal(etag))
+		})
+
+		It("calculates ETags per query and media type", func() {
+			// given
+			etag := serve(request).Header.Get("ETag")
+
+			// when
+			r := request.Clone(request.Context())
+			r.URL.RawQuery = "limit=1"
+			queryETag := serve(r).Header.Get("ETag")
+
+			r = request.Clone(request.Context())
+			r.Header.Set("Accept", "application/yaml")
+			yamlETag := serve(r).Header.Get("ETag")
+
+			// then
+			Expect(queryETag).NotTo(Equal(etag))
+			Expect(yamlETag).NotTo(Equal(etag))
+		})
+
+		It("does not set the ETag on Tables", func() {
+			// given
+			request.Header.Set("Accept", "application/json;as=Table;g=meta.k8s.io;v=v1")
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusOK))
+			Expect(rs.Header.Get("ETag")).To(BeEmpty())
+		})
+
+		It("does not set the ETag if the cache generation is unknown", func() {
+			// given
+			resourceVersion = ""
+
+			// when
+			rs := serve(request)
+
+			// then
+			Expect(rs.StatusCode).To(Equal(http.StatusOK))
+			Expect(rs.Header.Get("ETag")).To(BeEmpty())
+		})
+	})
+
+	It("translates watch events according to selectors", func(ctx context.Context) {
+		// given
+		fw := watch.NewFake()
+		lister := NamespaceListerMock{WatchNamespacesFunc: func(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+			return fw, nil
+		}}
+		handler := namespacelister.NewListNamespacesHandler(lister)
+		request = withSpecContext(ctx, request)
+		request.URL.RawQuery = "watch=true&labelSelector=konflux-ci.dev/type%3Dtenant"
+
+		w := httptest.NewRecorder()
+		go func() {
+			defer GinkgoRecover()
+			defer fw.Stop()
+
+			tenant := map[string]string{"konflux-ci.dev/type": "tenant"}
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-1"}})
+			fw.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-2", Labels: tenant}})
+			fw.Modify(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-1", Labels: tenant}})
+			fw.Modify(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-2"}})
+			fw.Delete(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-1", Labels: tenant}})
+		}()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+
+		d := json.NewDecoder(w.Result().Body)
+		ee := []string{}
+		for d.More() {
+			e := metav1.WatchEvent{}
+			Expect(d.Decode(&e)).To(Succeed())
+
+			ns := corev1.Namespace{}
+			Expect(json.Unmarshal(e.Object.Raw, &ns)).To(Succeed())
+			ee = append(ee, e.Type+"/"+ns.Name)
+		}
+		Expect(ee).To(Equal([]string{"ADDED/myns-2", "ADDED/myns-1", "DELETED/myns-2", "DELETED/myns-1"}))
+	})
 })
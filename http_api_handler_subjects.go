@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
+	"github.com/konflux-ci/namespace-lister/internal/log"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+)
+
+var _ http.Handler = &GetNamespaceSubjectsHandler{}
+
+// GetNamespaceSubjectsHandler lists the subjects having access to a namespace
+// the requesting user has access to
+type GetNamespaceSubjectsHandler struct {
+	lister NamespaceLister
+}
+
+func NewGetNamespaceSubjectsHandler(lister NamespaceLister) http.Handler {
+	return &GetNamespaceSubjectsHandler{
+		lister: lister,
+	}
+}
+
+func (h *GetNamespaceSubjectsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := log.GetLoggerFromContext(r.Context())
+
+	ud := r.Context().Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
+
+	// retrieve the namespace's subjects as the user
+	audit.RecordVerb(r.Context(), "get")
+	ctx, span := startSpan(r.Context(), "GetNamespaceSubjects")
+	ss, err := h.lister.GetNamespaceSubjects(ctx, ud.User.GetName(), ud.User.GetGroups(), r.PathValue("name"))
+	endSpan(span, err)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	// build response
+	b, err := json.Marshal(ss)
+	if err != nil {
+		status.WriteError(l, w, err)
+		return
+	}
+
+	w.Header().Add(constants.HttpContentType, constants.HttpContentTypeApplication)
+	addStaleWarning(w, r, h.lister)
+	write(l, w, b)
+}
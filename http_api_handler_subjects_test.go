@@ -0,0 +1,88 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+var _ = Describe("HttpHandlerSubjects", func() {
+	var request *http.Request
+
+	BeforeEach(func(tctx context.Context) {
+		var err error
+		ctx := context.WithValue(tctx, contextkey.ContextKeyUserDetails,
+			&authenticator.Response{
+				User: &user.DefaultInfo{
+					Name:   "myuser",
+					Groups: []string{"mygroup"},
+				},
+			})
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/namespaces/myns/subjects", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.SetPathValue("name", "myns")
+	})
+
+	It("lists the subjects having access to the namespace", func() {
+		// given
+		expected := namespacelister.NamespaceSubjects{
+			Namespace:       "myns",
+			Users:           []namespacelister.NamespaceSubject{{Name: "myuser", Access: "user"}},
+			Groups:          []namespacelister.NamespaceSubject{},
+			ServiceAccounts: []namespacelister.NamespaceSubject{{Name: "mysa", Namespace: "myns", Access: "serviceaccount"}},
+		}
+		lister := NamespaceListerMock{GetNamespaceSubjectsFunc: func(ctx context.Context, username string, groups []string, name string) (*namespacelister.NamespaceSubjects, error) {
+			Expect(username).To(Equal("myuser"))
+			Expect(groups).To(Equal([]string{"mygroup"}))
+			Expect(name).To(Equal("myns"))
+			return &expected, nil
+		}}
+		handler := namespacelister.NewGetNamespaceSubjectsHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
+		ss := namespacelister.NamespaceSubjects{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&ss)).To(Succeed())
+		Expect(ss).To(Equal(expected))
+	})
+
+	It("returns a NotFound Status when the namespace is not accessible", func() {
+		// given
+		lister := NamespaceListerMock{GetNamespaceSubjectsFunc: func(ctx context.Context, username string, groups []string, name string) (*namespacelister.NamespaceSubjects, error) {
+			return nil, kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+		}}
+		handler := namespacelister.NewGetNamespaceSubjectsHandler(lister)
+
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, request)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusNotFound))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Reason).To(Equal(metav1.StatusReasonNotFound))
+		Expect(s.Details.Name).To(Equal("myns"))
+	})
+})
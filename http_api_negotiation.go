@@ -0,0 +1,100 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/endpoints/handlers/negotiation"
+)
+
+var (
+	// scheme holds the types the namespace endpoints can reply with
+	scheme = runtime.NewScheme()
+	// codecs provides the serializers for the media types supported by the namespace endpoints
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(metav1.AddMetaToScheme(scheme))
+}
+
+var _ negotiation.EndpointRestrictions = namespaceEndpointRestrictions{}
+
+// namespaceEndpointRestrictions allows clients to request namespaces to be converted to Tables.
+// As Tables can not be encoded in protobuf, the conversion is allowed for text media types only.
+type namespaceEndpointRestrictions struct{}
+
+func (namespaceEndpointRestrictions) AllowsMediaTypeTransform(_, mimeSubType string, target *schema.GroupVersionKind) bool {
+	if target == nil {
+		return true
+	}
+	return isTableRequested(target) && mimeSubType != "vnd.kubernetes.protobuf"
+}
+
+func (namespaceEndpointRestrictions) AllowsServerVersion(string) bool { return false }
+
+func (namespaceEndpointRestrictions) AllowsStreamSchema(s string) bool { return s == "watch" }
+
+// responseFormat describes how to encode a reply as negotiated with the client
+type responseFormat struct {
+	info runtime.SerializerInfo
+
+	// tableOptions is set if the client requested the reply to be converted to a Table
+	tableOptions *metav1.TableOptions
+}
+
+// negotiateResponseFormat negotiates the format of the reply via the Accept header.
+// If streaming is requested, only media types supporting streams are accepted.
+// A NotAcceptable error implementing the APIStatus interface is returned
+// if no supported media type has been requested.
+func negotiateResponseFormat(r *http.Request, stream bool) (*responseFormat, error) {
+	mt, info, err := negotiation.NegotiateOutputMediaType(r, codecs, namespaceEndpointRestrictions{})
+	if err != nil {
+		return nil, err
+	}
+	if stream && info.StreamSerializer == nil {
+		_, supported := negotiation.MediaTypesForSerializer(codecs)
+		return nil, negotiation.NewNotAcceptableError(supported)
+	}
+
+	f := &responseFormat{info: info}
+	if isTableRequested(mt.Convert) {
+		if f.tableOptions, err = decodeTableOptions(r); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// ContentType returns the value for the Content-Type header of the reply
+func (f *responseFormat) ContentType(stream bool) string {
+	switch {
+	case f.info.MediaType == runtime.ContentTypeJSON:
+		return constants.HttpContentTypeApplication
+	case stream:
+		return f.info.MediaType + ";stream=watch"
+	default:
+		return f.info.MediaType
+	}
+}
+
+// Encode encodes the object in the negotiated media type
+func (f *responseFormat) Encode(o runtime.Object) ([]byte, error) {
+	return runtime.Encode(codecs.WithoutConversion().EncoderForVersion(f.info.Serializer, nil), o)
+}
+
+// NewStreamEncoder returns an encoder that writes framed objects
+// in the negotiated media type on the provided writer
+func (f *responseFormat) NewStreamEncoder(w io.Writer) streaming.Encoder {
+	s := f.info.StreamSerializer
+	return streaming.NewEncoder(s.NewFrameWriter(w), codecs.WithoutConversion().EncoderForVersion(s.Serializer, nil))
+}
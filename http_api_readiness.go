@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+)
+
+const (
+	readinessCheckResourceCache   string = "resource-cache-synced"
+	readinessCheckAccessCache     string = "access-cache-synced"
+	readinessCheckAccessStaleness string = "access-cache-fresh"
+)
+
+// AccessCacheSynchronizationStatus reports on the synchronization of the access cache
+type AccessCacheSynchronizationStatus interface {
+	// LastSynchTime returns the time of the last successful synchronization,
+	// or the zero time if the cache has never been synchronized
+	LastSynchTime() time.Time
+	// DataTime returns the time the served data has been calculated at, either by the last
+	// successful synchronization or by the one the restored snapshot has been taken after.
+	// It returns the zero time if the cache has no data.
+	DataTime() time.Time
+}
+
+// NewReadinessChecks builds the checks reporting whether the server is ready to serve requests.
+// The server is ready once the resource cache has synced and the access cache has been
+// successfully synchronized.
+// If stalenessThreshold is greater than zero, the server is not ready when the last
+// successful synchronization of the access cache is older than the threshold.
+// If readyOnSnapshot is true, data restored from a snapshot is considered as synchronized
+// at the time the snapshot has been taken.
+func NewReadinessChecks(resourceCacheSynced func() bool, accessCache AccessCacheSynchronizationStatus, stalenessThreshold time.Duration, readyOnSnapshot bool) []healthz.HealthChecker {
+	synchTime := accessCache.LastSynchTime
+	if readyOnSnapshot {
+		synchTime = accessCache.DataTime
+	}
+
+	cc := []healthz.HealthChecker{
+		healthz.NamedCheck(readinessCheckResourceCache, func(_ *http.Request) error {
+			if !resourceCacheSynced() {
+				return errors.New("resource cache informers have not synced yet")
+			}
+			return nil
+		}),
+		healthz.NamedCheck(readinessCheckAccessCache, func(_ *http.Request) error {
+			if synchTime().IsZero() {
+				return errors.New("access cache has not been synchronized yet")
+			}
+			return nil
+		}),
+	}
+
+	if stalenessThreshold > 0 {
+		cc = append(cc, healthz.NamedCheck(readinessCheckAccessStaleness, func(_ *http.Request) error {
+			t := synchTime()
+			if age := time.Since(t); !t.IsZero() && age > stalenessThreshold {
+				return fmt.Errorf("access cache last synchronized %s ago, more than the %s threshold", age.Round(time.Second), stalenessThreshold)
+			}
+			return nil
+		}))
+	}
+
+	return cc
+}
+
+// readinessMux registers the readiness handlers on a ServeMux for GET requests only.
+// Failing checks are reported with a ServiceUnavailable status code.
+type readinessMux struct {
+	*http.ServeMux
+}
+
+func (m readinessMux) Handle(pattern string, handler http.Handler) {
+	m.ServeMux.Handle(http.MethodGet+" "+pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(&serviceUnavailableResponseWriter{w}, r)
+	}))
+}
+
+// serviceUnavailableResponseWriter replaces the InternalServerError
+// status code healthz uses for failing checks with ServiceUnavailable
+type serviceUnavailableResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *serviceUnavailableResponseWriter) WriteHeader(code int) {
+	if code == http.StatusInternalServerError {
+		code = http.StatusServiceUnavailable
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
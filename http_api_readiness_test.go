@@ -0,0 +1,159 @@
+package main_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+)
+
+type accessCacheSynchronizationStatusMock struct {
+	lastSynchTime func() time.Time
+	dataTime      func() time.Time
+}
+
+func (m accessCacheSynchronizationStatusMock) LastSynchTime() time.Time { return m.lastSynchTime() }
+
+func (m accessCacheSynchronizationStatusMock) DataTime() time.Time { return m.dataTime() }
+
+// synchronizedAt mocks an access cache synchronized at the time returned by the provided function
+func synchronizedAt(t func() time.Time) accessCacheSynchronizationStatusMock {
+	return accessCacheSynchronizationStatusMock{lastSynchTime: t, dataTime: t}
+}
+
+// restoredAt mocks an access cache restored from a snapshot taken at the provided time
+// and not synchronized since
+func restoredAt(t time.Time) accessCacheSynchronizationStatusMock {
+	return accessCacheSynchronizationStatusMock{
+		lastSynchTime: func() time.Time { return time.Time{} },
+		dataTime:      func() time.Time { return t },
+	}
+}
+
+var _ = Describe("Readiness", func() {
+	var resourceCacheSynced bool
+	var lastSynch time.Time
+	var handler http.Handler
+
+	BeforeEach(func() {
+		resourceCacheSynced, lastSynch = true, time.Now()
+
+		ar := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			return nil, false, nil
+		})
+		checks := namespacelister.NewReadinessChecks(
+			func() bool { return resourceCacheSynced },
+			synchronizedAt(func() time.Time { return lastSynch }),
+			time.Hour,
+			false,
+		)
+		handler = namespacelister.NewAPIServer(slog.Default(), ar, NamespaceListerMock{}, nil, checks...).Handler
+	})
+
+	get := func(ctx context.Context, path string) *http.Response {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	It("is ready when caches are synchronized", func(ctx context.Context) {
+		Expect(get(ctx, "/readyz").StatusCode).To(Equal(http.StatusOK))
+	})
+
+	DescribeTable("is not ready", func(ctx context.Context, setup func(), failingCheck string) {
+		// given
+		setup()
+
+		// when
+		rsp := get(ctx, "/readyz?verbose")
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		b, err := io.ReadAll(rsp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("[-]" + failingCheck + " failed"))
+		Expect(get(ctx, "/readyz/"+failingCheck).StatusCode).To(Equal(http.StatusServiceUnavailable))
+	},
+		Entry("when the resource cache has not synced", func() { resourceCacheSynced = false }, "resource-cache-synced"),
+		Entry("when the access cache has not been synchronized", func() { lastSynch = time.Time{} }, "access-cache-synced"),
+		Entry("when the access cache is stale", func() { lastSynch = time.Now().Add(-2 * time.Hour) }, "access-cache-fresh"),
+	)
+
+	It("lists each check in verbose mode", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, "/readyz?verbose")
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		b, err := io.ReadAll(rsp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(And(
+			ContainSubstring("[+]resource-cache-synced ok"),
+			ContainSubstring("[+]access-cache-synced ok"),
+			ContainSubstring("[+]access-cache-fresh ok"),
+			ContainSubstring("readyz check passed"),
+		))
+	})
+
+	It("does not check staleness if no threshold is set", func(ctx context.Context) {
+		// given
+		checks := namespacelister.NewReadinessChecks(
+			func() bool { return true },
+			synchronizedAt(func() time.Time { return time.Now().Add(-24 * time.Hour) }),
+			0,
+			false,
+		)
+
+		// then
+		Expect(checks).To(HaveLen(2))
+		for _, c := range checks {
+			Expect(c.Check(nil)).To(Succeed())
+		}
+	})
+
+	It("is not ready with data restored from a snapshot", func() {
+		// given
+		checks := namespacelister.NewReadinessChecks(
+			func() bool { return true },
+			restoredAt(time.Now()),
+			time.Hour,
+			false,
+		)
+
+		// then
+		Expect(checks).To(HaveLen(3))
+		Expect(checks[1].Name()).To(Equal("access-cache-synced"))
+		Expect(checks[1].Check(nil)).To(MatchError("access cache has not been synchronized yet"))
+	})
+
+	DescribeTable("is ready with data restored from a snapshot if enabled", func(snapshotTime time.Time, matchFresh types.GomegaMatcher) {
+		// given
+		checks := namespacelister.NewReadinessChecks(
+			func() bool { return true },
+			restoredAt(snapshotTime),
+			time.Hour,
+			true,
+		)
+
+		// then
+		Expect(checks).To(HaveLen(3))
+		Expect(checks[1].Check(nil)).To(Succeed())
+		Expect(checks[2].Name()).To(Equal("access-cache-fresh"))
+		Expect(checks[2].Check(nil)).To(matchFresh)
+	},
+		Entry("when the snapshot is recent", time.Now(), Succeed()),
+		Entry("unless the snapshot is older than the threshold", time.Now().Add(-2*time.Hour), MatchError(ContainSubstring("more than the 1h0m0s threshold"))),
+	)
+})
@@ -6,18 +6,36 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/konflux-ci/namespace-lister/internal/audit"
 	"github.com/konflux-ci/namespace-lister/internal/http/middleware"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
 	"github.com/konflux-ci/namespace-lister/internal/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/server/healthz"
 )
 
 const (
 	patternGetNamespaces string = "GET /api/v1/namespaces"
+	patternGetNamespace  string = "GET /api/v1/namespaces/{name}"
+	patternGetSubjects   string = "GET /api/v1/namespaces/{name}/subjects"
+	patternGetExplain    string = "GET /admin/v1/namespaces/{name}/explain"
 	patternHealthz       string = "GET /healthz"
-	patternReadyz        string = "GET /readyz"
+	pathReadyz           string = "/readyz"
+
+	patternNamespaces string = "/api/v1/namespaces"
+	patternNamespace  string = "/api/v1/namespaces/{name}"
+	patternSubjects   string = "/api/v1/namespaces/{name}/subjects"
+	patternExplain    string = "/admin/v1/namespaces/{name}/explain"
+	patternNotFound   string = "/"
 )
 
 // APIServer is an HTTP server that serves the List Namespace endpoint
@@ -25,34 +43,93 @@ type APIServer struct {
 	*http.Server
 	useTLS  bool
 	tlsOpts []func(*tls.Config)
+
+	// impersonation authorizes impersonation requests.
+	// If nil, impersonation headers are ignored.
+	impersonation authorizer.Authorizer
+	// auditor records the audit events of the requests.
+	// If nil, requests are not audited.
+	auditor *audit.Auditor
+	// explainer explains the access of subjects to namespaces to the callers allowed by explainAuthorizer.
+	// If nil, the admin endpoints are not served.
+	explainer         *AccessExplainer
+	explainAuthorizer authorizer.Authorizer
 }
 
-func healthz(response http.ResponseWriter, _ *http.Request) {
+func alive(response http.ResponseWriter, _ *http.Request) {
 	response.WriteHeader(http.StatusOK)
 }
 
-// NewAPIServer builds a new APIServer
-func NewAPIServer(l *slog.Logger, ar authenticator.Request, lister NamespaceLister, reg prometheus.Registerer) *APIServer {
+// methodNotAllowed replies with a MethodNotAllowed Status
+func methodNotAllowed(l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodHead}, ", "))
+		status.Write(l, w, kerrors.NewMethodNotSupported(corev1.Resource("namespaces"), r.Method).Status())
+	}
+}
+
+// notFound replies with a NotFound Status
+func notFound(l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status.Write(l, w, metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusNotFound,
+			Reason:  metav1.StatusReasonNotFound,
+			Message: "the server could not find the requested resource",
+		})
+	}
+}
+
+// spanNameFromPattern names the request's span after the pattern that matched the request
+func spanNameFromPattern(operation string, r *http.Request) string {
+	if r.Pattern == "" {
+		return operation
+	}
+	return r.Pattern
+}
+
+// NewAPIServer builds a new APIServer.
+// The server is reported as ready when all the provided readiness checks pass.
+func NewAPIServer(l *slog.Logger, ar authenticator.Request, lister NamespaceLister, reg prometheus.Registerer, readinessChecks ...healthz.HealthChecker) *APIServer {
+	// configure the namespaces endpoints
+	api := http.NewServeMux()
+	api.Handle(patternGetNamespaces, NewListNamespacesHandler(lister))
+	api.Handle(patternGetNamespace, NewGetNamespaceHandler(lister))
+	api.Handle(patternGetSubjects, NewGetNamespaceSubjectsHandler(lister))
+	s := &APIServer{}
+	api.Handle(patternGetExplain, s.explainHandler(l))
+	ah := otelhttp.NewHandler(middleware.AddMetricsMiddleware(reg,
+		middleware.AddInjectLoggerMiddleware(*l,
+			middleware.AddLogCorrelationIDMiddleware(
+				middleware.AddAuthnMiddleware(ar,
+					s.addAuditMiddleware(
+						s.addImpersonationMiddleware(
+							middleware.AddLogRequestMiddleware(
+								addAccessProfileMiddleware(api)))))))),
+		"namespace-lister",
+		otelhttp.WithSpanNameFormatter(spanNameFromPattern))
+
 	// configure the server
 	h := http.NewServeMux()
-	h.Handle(patternGetNamespaces,
-		middleware.AddMetricsMiddleware(reg,
-			middleware.AddInjectLoggerMiddleware(*l,
-				middleware.AddLogCorrelationIDMiddleware(
-					middleware.AddAuthnMiddleware(ar,
-						middleware.AddLogRequestMiddleware(
-							NewListNamespacesHandler(lister)))))))
-
-	h.HandleFunc(patternHealthz, healthz)
-	h.HandleFunc(patternReadyz, healthz)
-
-	return &APIServer{
-		Server: &http.Server{
-			Addr:              getAddress(),
-			Handler:           h,
-			ReadHeaderTimeout: 3 * time.Second,
-		},
+	h.Handle(patternGetNamespaces, ah)
+	h.Handle(patternGetNamespace, ah)
+	h.Handle(patternGetSubjects, ah)
+	h.Handle(patternGetExplain, ah)
+	h.Handle(patternNamespaces, methodNotAllowed(l))
+	h.Handle(patternNamespace, methodNotAllowed(l))
+	h.Handle(patternSubjects, methodNotAllowed(l))
+	h.Handle(patternExplain, methodNotAllowed(l))
+	h.Handle(patternNotFound, notFound(l))
+
+	h.HandleFunc(patternHealthz, alive)
+	healthz.InstallPathHandler(readinessMux{h}, pathReadyz, readinessChecks...)
+
+	s.Server = &http.Server{
+		Addr:              getAddress(),
+		Handler:           h,
+		ReadHeaderTimeout: 3 * time.Second,
 	}
+	return s
 }
 
 // WithTLS enables the TLS Support
@@ -67,6 +144,60 @@ func (s *APIServer) WithTLSOpts(tlsOpts ...func(*tls.Config)) *APIServer {
 	return s
 }
 
+// WithImpersonation enables serving requests as the users impersonated via the
+// impersonation headers, if the provided authorizer allows the callers to impersonate them
+func (s *APIServer) WithImpersonation(az authorizer.Authorizer) *APIServer {
+	s.impersonation = az
+	return s
+}
+
+// addImpersonationMiddleware impersonates the requested users if impersonation is enabled
+func (s *APIServer) addImpersonationMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.impersonation == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		addImpersonationMiddleware(s.impersonation, next).ServeHTTP(w, r)
+	}
+}
+
+// WithAuditor enables recording the audit events of the requests with the provided auditor
+func (s *APIServer) WithAuditor(a *audit.Auditor) *APIServer {
+	s.auditor = a
+	return s
+}
+
+// addAuditMiddleware records the audit events of the requests if auditing is enabled
+func (s *APIServer) addAuditMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auditor == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		middleware.AddAuditMiddleware(s.auditor, next).ServeHTTP(w, r)
+	}
+}
+
+// WithAccessExplainer enables the admin endpoint explaining the access of subjects to namespaces.
+// Callers are required to be allowed by the provided authorizer to get the endpoint's non-resource URL.
+func (s *APIServer) WithAccessExplainer(e *AccessExplainer, az authorizer.Authorizer) *APIServer {
+	s.explainer = e
+	s.explainAuthorizer = az
+	return s
+}
+
+// explainHandler explains the access of subjects to namespaces if the admin endpoint is enabled
+func (s *APIServer) explainHandler(l *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.explainer == nil {
+			notFound(l).ServeHTTP(w, r)
+			return
+		}
+		NewExplainAccessHandler(s.explainer, s.explainAuthorizer).ServeHTTP(w, r)
+	}
+}
+
 // Start starts the APIServer blocking the current routine.
 // It monitors in a separate routine shutdown requests by waiting
 // for the provided context to be invalidated.
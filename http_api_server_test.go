@@ -0,0 +1,54 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+)
+
+var _ = Describe("APIServer", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		ar := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			return nil, false, nil
+		})
+		handler = namespacelister.NewAPIServer(slog.Default(), ar, NamespaceListerMock{}, nil).Handler
+	})
+
+	DescribeTable("replies with a Status on errors", func(ctx context.Context, method, path string, expectedCode int, expectedReason metav1.StatusReason) {
+		// given
+		r, err := http.NewRequestWithContext(ctx, method, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+
+		// when
+		handler.ServeHTTP(w, r)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(expectedCode))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Status).To(Equal(metav1.StatusFailure))
+		Expect(s.Code).To(BeEquivalentTo(expectedCode))
+		Expect(s.Reason).To(Equal(expectedReason))
+		Expect(s.Message).NotTo(BeEmpty())
+	},
+		Entry("unauthenticated list", http.MethodGet, "/api/v1/namespaces", http.StatusUnauthorized, metav1.StatusReasonUnauthorized),
+		Entry("unauthenticated get", http.MethodGet, "/api/v1/namespaces/myns", http.StatusUnauthorized, metav1.StatusReasonUnauthorized),
+		Entry("method not allowed on list", http.MethodPost, "/api/v1/namespaces", http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed),
+		Entry("method not allowed on namespace", http.MethodDelete, "/api/v1/namespaces/myns", http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed),
+		Entry("unknown path", http.MethodGet, "/api/v1/pods", http.StatusNotFound, metav1.StatusReasonNotFound),
+	)
+})
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
+	"github.com/konflux-ci/namespace-lister/internal/log"
+	authnv1 "k8s.io/api/authentication/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+const verbImpersonate string = "impersonate"
+
+// addImpersonationMiddleware serves the request as the user requested via the Impersonate-User,
+// Impersonate-Group and Impersonate-Uid headers, if the authenticated caller is allowed to impersonate them.
+// The authenticated caller is stored in the request's context as the impersonator.
+// Requests without impersonation headers are served as the authenticated caller.
+func addImpersonationMiddleware(az authorizer.Authorizer, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := log.GetLoggerFromContext(r.Context())
+
+		un := r.Header.Get(authnv1.ImpersonateUserHeader)
+		gg := r.Header.Values(authnv1.ImpersonateGroupHeader)
+		uid := r.Header.Get(authnv1.ImpersonateUIDHeader)
+		extra := hasImpersonateExtraHeaders(r.Header)
+		switch {
+		case un == "" && len(gg) == 0 && uid == "" && !extra:
+			next.ServeHTTP(w, r)
+			return
+		case extra:
+			status.Write(l, w, kerrors.NewBadRequest("impersonating user extras is not supported").Status())
+			return
+		case un == "":
+			status.Write(l, w, kerrors.NewBadRequest("impersonating groups or UIDs requires impersonating a user").Status())
+			return
+		}
+
+		ud := r.Context().Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
+		u := &user.DefaultInfo{Name: un, UID: uid, Groups: gg}
+		if err := authorizeImpersonation(r.Context(), az, ud.User, u); err != nil {
+			l.Info("impersonation denied", "impersonator", ud.User.GetName(), "user", un, "error", err)
+			status.WriteError(l, w, err)
+			return
+		}
+		defaultImpersonatedGroups(u)
+
+		// serve the request as the impersonated user
+		l = l.With("impersonator", ud.User.GetName(), "impersonated-user", u.Name)
+		l.Info("request impersonated", "groups", u.Groups)
+		audit.RecordImpersonatedUser(r.Context(), u)
+		ctx := context.WithValue(r.Context(), contextkey.ContextKeyImpersonatorDetails, ud)
+		ctx = context.WithValue(ctx, contextkey.ContextKeyUserDetails, &authenticator.Response{User: u})
+		ctx = log.SetLoggerIntoContext(ctx, l)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// authorizeImpersonation checks the impersonator can impersonate the user, its groups and its UID.
+// A Forbidden error is returned for the first subject the impersonator can not impersonate.
+func authorizeImpersonation(ctx context.Context, az authorizer.Authorizer, impersonator user.Info, u *user.DefaultInfo) error {
+	aa := make([]authorizer.AttributesRecord, 0, len(u.Groups)+2)
+	if ns, n, err := serviceaccount.SplitUsername(u.Name); err == nil {
+		aa = append(aa, authorizer.AttributesRecord{Resource: "serviceaccounts", Namespace: ns, Name: n})
+	} else {
+		aa = append(aa, authorizer.AttributesRecord{Resource: "users", Name: u.Name})
+	}
+	for _, g := range u.Groups {
+		aa = append(aa, authorizer.AttributesRecord{Resource: "groups", Name: g})
+	}
+	if u.UID != "" {
+		aa = append(aa, authorizer.AttributesRecord{APIGroup: authnv1.SchemeGroupVersion.Group, Resource: "uids", Name: u.UID})
+	}
+
+	for _, a := range aa {
+		a.User = impersonator
+		a.Verb = verbImpersonate
+		a.ResourceRequest = true
+
+		d, reason, err := az.Authorize(ctx, a)
+		if d == authorizer.DecisionAllow {
+			continue
+		}
+		if err != nil {
+			reason = cmp.Or(reason, err.Error())
+		}
+		return kerrors.NewForbidden(
+			schema.GroupResource{Group: a.APIGroup, Resource: a.Resource},
+			a.Name,
+			fmt.Errorf("user %q cannot impersonate %s: %s", impersonator.GetName(), a.Resource, reason))
+	}
+	return nil
+}
+
+// defaultImpersonatedGroups adds the groups the APIServer adds to impersonated users:
+// the service account groups when impersonating a service account without groups,
+// and the system:authenticated or system:unauthenticated virtual groups
+func defaultImpersonatedGroups(u *user.DefaultInfo) {
+	if ns, _, err := serviceaccount.SplitUsername(u.Name); err == nil && len(u.Groups) == 0 {
+		u.Groups = serviceaccount.MakeGroupNames(ns)
+	}
+
+	g := user.AllAuthenticated
+	if u.Name == user.Anonymous {
+		g = user.AllUnauthenticated
+	}
+	if !slices.Contains(u.Groups, g) {
+		u.Groups = append(u.Groups, g)
+	}
+}
+
+// hasImpersonateExtraHeaders returns true if any of the Impersonate-Extra- headers is set
+func hasImpersonateExtraHeaders(h http.Header) bool {
+	for k := range h {
+		if strings.HasPrefix(http.CanonicalHeaderKey(k), authnv1.ImpersonateUserExtraHeaderPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesImpersonationHeaders returns true if any of the authenticators in the chain
+// reads the user information from the impersonation headers
+func usesImpersonationHeaders(c *AuthenticationConfiguration) bool {
+	hh := []string{}
+	for _, a := range c.Authenticators {
+		switch {
+		case a.Header != nil:
+			hh = append(hh, a.Header.UsernameHeader, a.Header.GroupsHeader)
+		case a.RequestHeader != nil:
+			hh = slices.Concat(hh, a.RequestHeader.UsernameHeaders, a.RequestHeader.UIDHeaders, a.RequestHeader.GroupHeaders)
+		}
+	}
+	return slices.ContainsFunc(hh, func(h string) bool {
+		return strings.HasPrefix(http.CanonicalHeaderKey(h), "Impersonate-")
+	})
+}
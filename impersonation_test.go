@@ -0,0 +1,167 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+type auditSinkFunc func(context.Context, *audit.Event)
+
+func (f auditSinkFunc) Process(ctx context.Context, ev *audit.Event) { f(ctx, ev) }
+
+var _ = Describe("Impersonation", func() {
+	var (
+		server   *namespacelister.APIServer
+		handler  http.Handler
+		allowed  map[string]bool
+		checked  []authorizer.Attributes
+		listedAs *user.DefaultInfo
+	)
+
+	BeforeEach(func() {
+		allowed = map[string]bool{}
+		checked = nil
+		listedAs = nil
+
+		ar := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "admin"}}, true, nil
+		})
+		az := authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+			checked = append(checked, a)
+			if a.GetUser().GetName() == "admin" && a.GetVerb() == "impersonate" && allowed[a.GetResource()+"/"+a.GetName()] {
+				return authorizer.DecisionAllow, "", nil
+			}
+			return authorizer.DecisionNoOpinion, "not allowed", nil
+		})
+		lister := NamespaceListerMock{
+			ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				listedAs = &user.DefaultInfo{Name: username, Groups: groups}
+				return &corev1.NamespaceList{}, nil
+			},
+		}
+		server = namespacelister.NewAPIServer(slog.Default(), ar, lister, nil).WithImpersonation(az)
+		handler = server.Handler
+	})
+
+	list := func(ctx context.Context, headers http.Header) *http.Response {
+		GinkgoHelper()
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/namespaces", nil)
+		Expect(err).NotTo(HaveOccurred())
+		r.Header = headers
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	It("lists namespaces as the caller if no impersonation is requested", func(ctx context.Context) {
+		// when
+		rs := list(ctx, http.Header{})
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		Expect(listedAs.Name).To(Equal("admin"))
+		Expect(checked).To(BeEmpty())
+	})
+
+	It("lists namespaces as the impersonated user", func(ctx context.Context) {
+		// given
+		allowed["users/myuser"] = true
+		allowed["groups/mygroup"] = true
+		allowed["uids/1234"] = true
+
+		// when
+		rs := list(ctx, http.Header{
+			"Impersonate-User":  {"myuser"},
+			"Impersonate-Group": {"mygroup"},
+			"Impersonate-Uid":   {"1234"},
+		})
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		Expect(listedAs.Name).To(Equal("myuser"))
+		Expect(listedAs.Groups).To(ConsistOf("mygroup", user.AllAuthenticated))
+		Expect(checked).To(HaveLen(3))
+	})
+
+	It("checks the impersonation of service accounts in their namespace", func(ctx context.Context) {
+		// given
+		allowed["serviceaccounts/mysa"] = true
+
+		// when
+		rs := list(ctx, http.Header{"Impersonate-User": {"system:serviceaccount:myns:mysa"}})
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		Expect(checked).To(HaveExactElements(HaveField("GetNamespace()", "myns")))
+		Expect(listedAs.Groups).To(ConsistOf(
+			"system:serviceaccounts", "system:serviceaccounts:myns", user.AllAuthenticated))
+	})
+
+	It("records both identities in the audit event", func(ctx context.Context) {
+		// given
+		allowed["users/myuser"] = true
+		var events []*audit.Event
+		sink := auditSinkFunc(func(_ context.Context, ev *audit.Event) { events = append(events, ev) })
+		handler = server.WithAuditor(audit.New(&audit.Policy{Rules: []audit.PolicyRule{{Level: audit.LevelMetadata}}}, sink)).Handler
+
+		// when
+		rs := list(ctx, http.Header{"Impersonate-User": {"myuser"}})
+
+		// then
+		Expect(rs.StatusCode).To(Equal(http.StatusOK))
+		Expect(events).To(HaveExactElements(And(
+			HaveField("User.Username", "admin"),
+			HaveField("ImpersonatedUser.Username", "myuser"),
+			HaveField("Verb", "list"),
+		)))
+	})
+
+	DescribeTable("rejects impersonation", func(ctx context.Context, allow []string, headers http.Header, expectedCode int, expectedReason metav1.StatusReason) {
+		// given
+		for _, a := range allow {
+			allowed[a] = true
+		}
+
+		// when
+		rs := list(ctx, headers)
+
+		// then
+		Expect(rs.StatusCode).To(Equal(expectedCode))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(rs.Body).Decode(&s)).To(Succeed())
+		Expect(s.Reason).To(Equal(expectedReason))
+		Expect(listedAs).To(BeNil())
+	},
+		Entry("of users the caller can not impersonate", nil,
+			http.Header{"Impersonate-User": {"myuser"}},
+			http.StatusForbidden, metav1.StatusReasonForbidden),
+		Entry("of groups the caller can not impersonate", []string{"users/myuser"},
+			http.Header{"Impersonate-User": {"myuser"}, "Impersonate-Group": {"mygroup"}},
+			http.StatusForbidden, metav1.StatusReasonForbidden),
+		Entry("of UIDs the caller can not impersonate", []string{"users/myuser"},
+			http.Header{"Impersonate-User": {"myuser"}, "Impersonate-Uid": {"1234"}},
+			http.StatusForbidden, metav1.StatusReasonForbidden),
+		Entry("of groups without user", []string{"groups/mygroup"},
+			http.Header{"Impersonate-Group": {"mygroup"}},
+			http.StatusBadRequest, metav1.StatusReasonBadRequest),
+		Entry("of user extras", []string{"users/myuser"},
+			http.Header{"Impersonate-User": {"myuser"}, "Impersonate-Extra-Scopes": {"all"}},
+			http.StatusBadRequest, metav1.StatusReasonBadRequest),
+	)
+})
@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// Event records who requested which namespaces, and when
+type Event struct {
+	// Level is the audit level the event has been recorded with
+	Level Level `json:"level"`
+	// CorrelationID is the correlation ID of the request
+	CorrelationID string `json:"correlationID,omitempty"`
+	// RequestURI is the URI of the request
+	RequestURI string `json:"requestURI"`
+	// Verb is the operation requested: list, watch, or get
+	Verb string `json:"verb,omitempty"`
+	// User is the authenticated user
+	User UserInfo `json:"user"`
+	// ImpersonatedUser is the user the request has been served as, if impersonated
+	ImpersonatedUser *UserInfo `json:"impersonatedUser,omitempty"`
+	// Authenticator is the name of the authenticator that authenticated the user
+	Authenticator string `json:"authenticator,omitempty"`
+	// ResponseCode is the HTTP status code of the reply
+	ResponseCode int `json:"responseCode"`
+	// NamespaceCount is the number of namespaces returned
+	NamespaceCount *int `json:"namespaceCount,omitempty"`
+	// Namespaces are the names of the namespaces returned.
+	// They are recorded only at the Namespaces level.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// CacheGeneration is the generation of the access cache data the namespaces have been retrieved from
+	CacheGeneration string `json:"cacheGeneration,omitempty"`
+	// RequestReceivedTimestamp is the time the request has been received at
+	RequestReceivedTimestamp metav1.MicroTime `json:"requestReceivedTimestamp"`
+	// Latency is the time taken to serve the request
+	Latency metav1.Duration `json:"latency"`
+
+	// watched are the names of the namespaces sent to a watching client
+	watched map[string]struct{}
+}
+
+// UserInfo is the user information recorded in the audit events
+type UserInfo struct {
+	Username string   `json:"username"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// NewUserInfo builds the UserInfo of the provided user
+func NewUserInfo(u user.Info) UserInfo {
+	return UserInfo{
+		Username: u.GetName(),
+		UID:      u.GetUID(),
+		Groups:   u.GetGroups(),
+	}
+}
+
+// Sink stores the audit events.
+// Implementations must not block serving requests.
+type Sink interface {
+	Process(ctx context.Context, ev *Event)
+}
+
+// Auditor records the audit events of the requests as defined by the policy
+type Auditor struct {
+	policy *Policy
+	sinks  []Sink
+}
+
+// New builds an Auditor storing the audit events in the provided sinks
+func New(policy *Policy, sinks ...Sink) *Auditor {
+	return &Auditor{
+		policy: policy,
+		sinks:  sinks,
+	}
+}
+
+// NewEvent builds the audit event of a request received now from the provided user.
+// If the policy does not audit the user's requests, nil is returned.
+func (a *Auditor) NewEvent(u user.Info) *Event {
+	lvl := a.policy.LevelFor(u)
+	if lvl == LevelNone {
+		return nil
+	}
+
+	return &Event{
+		Level:                    lvl,
+		User:                     NewUserInfo(u),
+		RequestReceivedTimestamp: metav1.NowMicro(),
+	}
+}
+
+// Process completes the event with the request's latency and stores it in all the sinks
+func (a *Auditor) Process(ctx context.Context, ev *Event) {
+	ev.Latency = metav1.Duration{Duration: time.Since(ev.RequestReceivedTimestamp.Time)}
+	for _, s := range a.sinks {
+		s.Process(ctx, ev)
+	}
+}
+
+// WithEvent stores the audit event in the context
+func WithEvent(ctx context.Context, ev *Event) context.Context {
+	return context.WithValue(ctx, contextkey.ContextKeyAuditEvent, ev)
+}
+
+// EventFrom retrieves the audit event from the context.
+// If the request is not audited, nil is returned.
+func EventFrom(ctx context.Context) *Event {
+	ev, _ := ctx.Value(contextkey.ContextKeyAuditEvent).(*Event)
+	return ev
+}
+
+// RecordVerb records in the audit event of the request the operation requested
+func RecordVerb(ctx context.Context, verb string) {
+	if ev := EventFrom(ctx); ev != nil {
+		ev.Verb = verb
+	}
+}
+
+// RecordImpersonatedUser records in the audit event of the request the user the request is served as
+func RecordImpersonatedUser(ctx context.Context, u user.Info) {
+	if ev := EventFrom(ctx); ev != nil {
+		ui := NewUserInfo(u)
+		ev.ImpersonatedUser = &ui
+	}
+}
+
+// RecordNamespaces records in the audit event of the request the namespaces returned
+// and the generation of the cache data they have been retrieved from.
+// Names are recorded only at the Namespaces level.
+func RecordNamespaces(ctx context.Context, generation string, nn []corev1.Namespace) {
+	ev := EventFrom(ctx)
+	if ev == nil {
+		return
+	}
+
+	c := len(nn)
+	ev.NamespaceCount = &c
+	ev.CacheGeneration = generation
+	if ev.Level != LevelNamespaces {
+		return
+	}
+	ev.Namespaces = make([]string, 0, len(nn))
+	for _, ns := range nn {
+		ev.Namespaces = append(ev.Namespaces, ns.Name)
+	}
+}
+
+// RecordWatchedNamespace records in the audit event of a watch request a namespace sent to the client,
+// either in the initial list or in a later change.
+// Each namespace is counted once, and names are recorded only at the Namespaces level.
+func RecordWatchedNamespace(ctx context.Context, name string) {
+	ev := EventFrom(ctx)
+	if ev == nil {
+		return
+	}
+
+	if _, ok := ev.watched[name]; ok {
+		return
+	}
+	if ev.watched == nil {
+		ev.watched = map[string]struct{}{}
+	}
+	ev.watched[name] = struct{}{}
+
+	c := len(ev.watched)
+	ev.NamespaceCount = &c
+	if ev.Level == LevelNamespaces {
+		ev.Namespaces = append(ev.Namespaces, name)
+	}
+}
@@ -0,0 +1,117 @@
+package audit_test
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+)
+
+// memorySink stores the audit events in memory
+type memorySink struct {
+	mu     sync.Mutex
+	events []*audit.Event
+}
+
+func (s *memorySink) Process(_ context.Context, ev *audit.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+var _ = Describe("Auditor", func() {
+	var (
+		sink    *memorySink
+		auditor *audit.Auditor
+	)
+
+	BeforeEach(func() {
+		sink = &memorySink{}
+		auditor = audit.New(&audit.Policy{Rules: []audit.PolicyRule{
+			{Level: audit.LevelNamespaces, Users: []string{"alice"}},
+			{Level: audit.LevelMetadata, Users: []string{"bob"}},
+		}}, sink)
+	})
+
+	namespaces := func(names ...string) []corev1.Namespace {
+		nn := make([]corev1.Namespace, 0, len(names))
+		for _, n := range names {
+			nn = append(nn, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: n}})
+		}
+		return nn
+	}
+
+	It("records the namespaces names at Namespaces level", func(ctx context.Context) {
+		// given
+		ev := auditor.NewEvent(&user.DefaultInfo{Name: "alice"})
+		ctx = audit.WithEvent(ctx, ev)
+
+		// when
+		audit.RecordVerb(ctx, "list")
+		audit.RecordNamespaces(ctx, "42", namespaces("ns-1", "ns-2"))
+		auditor.Process(ctx, ev)
+
+		// then
+		Expect(sink.events).To(HaveExactElements(And(
+			HaveField("Level", audit.LevelNamespaces),
+			HaveField("User.Username", "alice"),
+			HaveField("Verb", "list"),
+			HaveField("NamespaceCount", HaveValue(Equal(2))),
+			HaveField("Namespaces", HaveExactElements("ns-1", "ns-2")),
+			HaveField("CacheGeneration", "42"),
+		)))
+	})
+
+	It("records only the namespaces count at Metadata level", func(ctx context.Context) {
+		// given
+		ev := auditor.NewEvent(&user.DefaultInfo{Name: "bob"})
+		ctx = audit.WithEvent(ctx, ev)
+
+		// when
+		audit.RecordNamespaces(ctx, "42", nil)
+		auditor.Process(ctx, ev)
+
+		// then
+		Expect(sink.events).To(HaveExactElements(And(
+			HaveField("Level", audit.LevelMetadata),
+			HaveField("NamespaceCount", HaveValue(Equal(0))),
+			HaveField("Namespaces", BeEmpty()),
+		)))
+	})
+
+	It("counts each watched namespace once", func(ctx context.Context) {
+		// given
+		ev := auditor.NewEvent(&user.DefaultInfo{Name: "bob"})
+		ctx = audit.WithEvent(ctx, ev)
+
+		// when
+		audit.RecordVerb(ctx, "watch")
+		for _, n := range []string{"ns-1", "ns-2", "ns-1"} {
+			audit.RecordWatchedNamespace(ctx, n)
+		}
+		auditor.Process(ctx, ev)
+
+		// then
+		Expect(sink.events).To(HaveExactElements(And(
+			HaveField("Level", audit.LevelMetadata),
+			HaveField("Verb", "watch"),
+			HaveField("NamespaceCount", HaveValue(Equal(2))),
+			HaveField("Namespaces", BeEmpty()),
+		)))
+	})
+
+	It("does not audit requests at None level", func(ctx context.Context) {
+		// when
+		ev := auditor.NewEvent(&user.DefaultInfo{Name: "carol"})
+
+		// then
+		Expect(ev).To(BeNil())
+		Expect(func() { audit.RecordNamespaces(audit.WithEvent(ctx, ev), "42", namespaces("ns-1")) }).NotTo(Panic())
+	})
+})
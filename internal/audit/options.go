@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+)
+
+const defaultWebhookTimeout = 30 * time.Second
+
+// Options configures the audit subsystem
+type Options struct {
+	// PolicyFile is the path of the audit policy
+	PolicyFile string
+	// File configures the file sink. If nil, audit events are not written to file.
+	File *FileSinkOptions
+	// WebhookURL is the URL of the webhook sink. If empty, audit events are not sent to a webhook.
+	WebhookURL string
+}
+
+// GetOptionsFromEnv retrieves the audit configuration from environment variables.
+// If the audit policy is not set, auditing is disabled and nil is returned.
+func GetOptionsFromEnv() (*Options, error) {
+	opts := Options{
+		PolicyFile: os.Getenv(constants.EnvAuditPolicyFile),
+		WebhookURL: os.Getenv(constants.EnvAuditWebhookURL),
+	}
+	if opts.PolicyFile == "" {
+		return nil, nil
+	}
+
+	if p := os.Getenv(constants.EnvAuditLogPath); p != "" {
+		opts.File = &FileSinkOptions{Path: p}
+		for env, v := range map[string]*int{
+			constants.EnvAuditLogMaxSize:    &opts.File.MaxSize,
+			constants.EnvAuditLogMaxBackups: &opts.File.MaxBackups,
+			constants.EnvAuditLogMaxAge:     &opts.File.MaxAge,
+		} {
+			if err := getNonNegativeIntFromEnv(env, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &opts, nil
+}
+
+// getNonNegativeIntFromEnv parses the environment variable into the provided int, if set
+func getNonNegativeIntFromEnv(env string, v *int) error {
+	s, ok := os.LookupEnv(env)
+	if !ok || s == "" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return fmt.Errorf("invalid value %q of environment variable %s: a non-negative integer is required", s, env)
+	}
+	*v = i
+	return nil
+}
+
+// NewFromOptions builds an Auditor reading the audit policy and storing the audit events in the configured sinks.
+// The webhook sink sends the audit events in background until the provided context is invalidated.
+func NewFromOptions(ctx context.Context, opts Options) (*Auditor, error) {
+	if opts.File == nil && opts.WebhookURL == "" {
+		return nil, errors.New("at least one among the audit log path and the audit webhook URL is required")
+	}
+
+	p, err := ReadPolicyFile(opts.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []Sink{}
+	if opts.File != nil {
+		s := NewFileSink(*opts.File)
+		context.AfterFunc(ctx, func() { _ = s.Close() })
+		ss = append(ss, s)
+	}
+	if opts.WebhookURL != "" {
+		s := NewWebhookSink(opts.WebhookURL, &http.Client{Timeout: defaultWebhookTimeout})
+		go s.Run(ctx)
+		ss = append(ss, s)
+	}
+	return New(p, ss...), nil
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"sigs.k8s.io/yaml"
+)
+
+// Level defines the amount of information recorded in the audit events
+type Level string
+
+const (
+	// LevelNone disables auditing
+	LevelNone Level = "None"
+	// LevelMetadata records the request's metadata and the number of namespaces returned
+	LevelMetadata Level = "Metadata"
+	// LevelNamespaces records the request's metadata and the names of the namespaces returned
+	LevelNamespaces Level = "Namespaces"
+)
+
+// Policy defines the audit level of the requests.
+// Rules are evaluated in order, the first one matching the requesting user sets the level.
+// Requests not matching any rule are not audited.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule sets the audit level of the requests of the matching users
+type PolicyRule struct {
+	// Level is the audit level of the matching requests
+	Level Level `json:"level"`
+	// Users are the names of the users the rule applies to.
+	// If empty, the rule applies to all the users.
+	Users []string `json:"users,omitempty"`
+	// UserGroups are the groups of the users the rule applies to.
+	// A user matches if it is in at least one of them.
+	// If empty, the rule applies to all the groups.
+	UserGroups []string `json:"userGroups,omitempty"`
+}
+
+// LevelFor returns the audit level of the requests of the provided user
+func (p *Policy) LevelFor(u user.Info) Level {
+	for _, r := range p.Rules {
+		if r.matches(u) {
+			return r.Level
+		}
+	}
+	return LevelNone
+}
+
+// matches returns true if the rule applies to the provided user
+func (r *PolicyRule) matches(u user.Info) bool {
+	if len(r.Users) > 0 && !slices.Contains(r.Users, u.GetName()) {
+		return false
+	}
+	if len(r.UserGroups) > 0 && !slices.ContainsFunc(u.GetGroups(), func(g string) bool {
+		return slices.Contains(r.UserGroups, g)
+	}) {
+		return false
+	}
+	return true
+}
+
+// Validate checks all the rules set a known level
+func (p *Policy) Validate() error {
+	for i, r := range p.Rules {
+		switch r.Level {
+		case LevelNone, LevelMetadata, LevelNamespaces:
+		default:
+			return fmt.Errorf("rules[%d]: unknown level %q", i, r.Level)
+		}
+	}
+	return nil
+}
+
+// ReadPolicyFile reads and validates the audit policy stored at the provided path
+func ReadPolicyFile(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read audit policy file: %w", err)
+	}
+
+	p := Policy{}
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return nil, fmt.Errorf("invalid audit policy file %q: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid audit policy file %q: %w", path, err)
+	}
+	return &p, nil
+}
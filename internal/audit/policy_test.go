@@ -0,0 +1,62 @@
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+)
+
+var _ = Describe("Policy", func() {
+	var policy *audit.Policy
+
+	BeforeEach(func() {
+		p := filepath.Join(GinkgoT().TempDir(), "policy.yaml")
+		Expect(os.WriteFile(p, []byte(`
+rules:
+- level: None
+  users: [system:serviceaccount:monitoring:prober]
+- level: Namespaces
+  userGroups: [auditors, admins]
+- level: Namespaces
+  users: [alice]
+- level: Metadata
+`), 0o600)).To(Succeed())
+
+		var err error
+		policy, err = audit.ReadPolicyFile(p)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("sets the level of the first matching rule", func(u user.Info, expectedLevel audit.Level) {
+		Expect(policy.LevelFor(u)).To(Equal(expectedLevel))
+	},
+		Entry("excluded user", &user.DefaultInfo{Name: "system:serviceaccount:monitoring:prober", Groups: []string{"admins"}}, audit.LevelNone),
+		Entry("user in matching group", &user.DefaultInfo{Name: "bob", Groups: []string{"devs", "auditors"}}, audit.LevelNamespaces),
+		Entry("matching user", &user.DefaultInfo{Name: "alice"}, audit.LevelNamespaces),
+		Entry("any other user", &user.DefaultInfo{Name: "carol", Groups: []string{"devs"}}, audit.LevelMetadata),
+	)
+
+	It("does not audit users not matching any rule", func() {
+		Expect((&audit.Policy{}).LevelFor(&user.DefaultInfo{Name: "alice"})).To(Equal(audit.LevelNone))
+	})
+
+	DescribeTable("rejects invalid policy files", func(content string) {
+		// given
+		p := filepath.Join(GinkgoT().TempDir(), "policy.yaml")
+		Expect(os.WriteFile(p, []byte(content), 0o600)).To(Succeed())
+
+		// when
+		_, err := audit.ReadPolicyFile(p)
+
+		// then
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("with unknown level", "rules:\n- level: RequestResponse\n"),
+		Entry("with unknown fields", "rules:\n- level: Metadata\n  verbs: [list]\n"),
+	)
+})
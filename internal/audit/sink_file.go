@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/konflux-ci/namespace-lister/internal/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var _ Sink = &FileSink{}
+
+// FileSinkOptions configures the file the audit events are written to, and its rotation
+type FileSinkOptions struct {
+	// Path is the path of the file
+	Path string
+	// MaxSize is the maximum size in megabytes of the file before it gets rotated
+	MaxSize int
+	// MaxBackups is the maximum number of rotated files to retain
+	MaxBackups int
+	// MaxAge is the maximum number of days to retain rotated files for
+	MaxAge int
+}
+
+// FileSink writes the audit events as JSON lines to a file rotated by size
+type FileSink struct {
+	w *lumberjack.Logger
+}
+
+// NewFileSink builds a FileSink writing to the configured file
+func NewFileSink(opts FileSinkOptions) *FileSink {
+	return &FileSink{
+		w: &lumberjack.Logger{
+			Filename:   opts.Path,
+			MaxSize:    opts.MaxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAge,
+		},
+	}
+}
+
+// Process writes the audit event to the file
+func (s *FileSink) Process(ctx context.Context, ev *Event) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		log.GetLoggerFromContext(ctx).Error("error encoding audit event", "error", err)
+		return
+	}
+
+	if _, err := s.w.Write(append(b, '\n')); err != nil {
+		log.GetLoggerFromContext(ctx).Error("error writing audit event", "error", err)
+	}
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.w.Close()
+}
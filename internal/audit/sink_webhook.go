@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/log"
+)
+
+const (
+	defaultWebhookBufferSize = 10000
+	defaultWebhookBatchSize  = 400
+)
+
+var _ Sink = &WebhookSink{}
+
+// EventList is the batch of audit events sent to the webhook
+type EventList struct {
+	Items []*Event `json:"items"`
+}
+
+// WebhookSink sends the audit events in batches to a webhook.
+// Events are buffered and sent in background, so that serving requests is not blocked.
+// Events are dropped if the buffer is full.
+type WebhookSink struct {
+	url       string
+	client    *http.Client
+	events    chan *Event
+	batchSize int
+}
+
+// NewWebhookSink builds a WebhookSink POSTing the audit events to the provided URL.
+// Events are sent only after the sink is started with Run.
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	return &WebhookSink{
+		url:       url,
+		client:    client,
+		events:    make(chan *Event, defaultWebhookBufferSize),
+		batchSize: defaultWebhookBatchSize,
+	}
+}
+
+// Process buffers the audit event for it to be sent to the webhook.
+// If the buffer is full, the event is dropped.
+func (s *WebhookSink) Process(ctx context.Context, ev *Event) {
+	select {
+	case s.events <- ev:
+	default:
+		log.GetLoggerFromContext(ctx).Error("audit webhook buffer is full, dropping audit event", "correlation-id", ev.CorrelationID)
+	}
+}
+
+// Run sends the buffered audit events to the webhook until the context is invalidated.
+// Events buffered together are sent in the same batch.
+func (s *WebhookSink) Run(ctx context.Context) {
+	l := log.GetLoggerFromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-s.events:
+			el := EventList{Items: []*Event{ev}}
+			s.drain(&el)
+			if err := s.send(ctx, &el); err != nil {
+				l.Error("error sending audit events to webhook, dropping them", "events", len(el.Items), "error", err)
+			}
+		}
+	}
+}
+
+// drain moves the buffered events to the batch until the batch is full
+func (s *WebhookSink) drain(el *EventList) {
+	for len(el.Items) < s.batchSize {
+		select {
+		case ev := <-s.events:
+			el.Items = append(el.Items, ev)
+		default:
+			return
+		}
+	}
+}
+
+// send POSTs the batch of events to the webhook
+func (s *WebhookSink) send(ctx context.Context, el *EventList) error {
+	b, err := json.Marshal(el)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	rs, err := s.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode < 200 || rs.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", rs.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+)
+
+var _ = Describe("Sinks", func() {
+	newEvent := func(cid string) *audit.Event {
+		return &audit.Event{Level: audit.LevelMetadata, CorrelationID: cid, User: audit.UserInfo{Username: "alice"}}
+	}
+
+	It("writes events as JSON lines to file", func(ctx context.Context) {
+		// given
+		p := filepath.Join(GinkgoT().TempDir(), "audit.log")
+		s := audit.NewFileSink(audit.FileSinkOptions{Path: p})
+		DeferCleanup(s.Close)
+
+		// when
+		s.Process(ctx, newEvent("1"))
+		s.Process(ctx, newEvent("2"))
+
+		// then
+		f, err := os.Open(p)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(f.Close)
+		cids := []string{}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			ev := audit.Event{}
+			Expect(json.Unmarshal(sc.Bytes(), &ev)).To(Succeed())
+			cids = append(cids, ev.CorrelationID)
+		}
+		Expect(cids).To(HaveExactElements("1", "2"))
+	})
+
+	It("sends events to the webhook", func() {
+		// given
+		received := make(chan string, 10)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			el := audit.EventList{}
+			Expect(json.NewDecoder(r.Body).Decode(&el)).To(Succeed())
+			for _, ev := range el.Items {
+				received <- ev.CorrelationID
+			}
+		}))
+		DeferCleanup(srv.Close)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+		s := audit.NewWebhookSink(srv.URL, srv.Client())
+		go s.Run(ctx)
+
+		// when
+		s.Process(ctx, newEvent("1"))
+		s.Process(ctx, newEvent("2"))
+
+		// then
+		Eventually(received).Should(Receive(Equal("1")))
+		Eventually(received).Should(Receive(Equal("2")))
+	})
+})
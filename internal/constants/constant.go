@@ -1,14 +1,48 @@
 package constants
 
 const (
-	EnvLogLevel          string = "LOG_LEVEL"
-	EnvUsernameHeader    string = "AUTH_USERNAME_HEADER"
-	EnvGroupsHeader      string = "AUTH_GROUPS_HEADER"
-	EnvAddress           string = "ADDRESS"
-	EnvCacheResyncPeriod string = "CACHE_RESYNC_PERIOD"
+	EnvLogLevel                string = "LOG_LEVEL"
+	EnvUsernameHeader          string = "AUTH_USERNAME_HEADER"
+	EnvGroupsHeader            string = "AUTH_GROUPS_HEADER"
+	EnvAddress                 string = "ADDRESS"
+	EnvCacheResyncPeriod       string = "CACHE_RESYNC_PERIOD"
+	EnvCacheSynchConcurrency   string = "CACHE_SYNCH_CONCURRENCY"
+	EnvCacheSnapshotPath       string = "CACHE_SNAPSHOT_PATH"
+	EnvCacheSnapshotMaxAge     string = "CACHE_SNAPSHOT_MAX_AGE"
+	EnvCacheStalenessThreshold string = "CACHE_STALENESS_THRESHOLD"
+	EnvCacheSnapshotReady      string = "CACHE_SNAPSHOT_READY"
+	EnvAccessVerb              string = "ACCESS_VERB"
+	EnvAccessResource          string = "ACCESS_RESOURCE"
+	EnvAccessProfiles          string = "ACCESS_PROFILES"
+
+	EnvRequestHeaderClientCAFile       string = "AUTH_REQUESTHEADER_CLIENT_CA_FILE"
+	EnvRequestHeaderAllowedNames       string = "AUTH_REQUESTHEADER_ALLOWED_NAMES"
+	EnvRequestHeaderUsernameHeaders    string = "AUTH_REQUESTHEADER_USERNAME_HEADERS"
+	EnvRequestHeaderUIDHeaders         string = "AUTH_REQUESTHEADER_UID_HEADERS"
+	EnvRequestHeaderGroupHeaders       string = "AUTH_REQUESTHEADER_GROUP_HEADERS"
+	EnvRequestHeaderExtraHeadersPrefix string = "AUTH_REQUESTHEADER_EXTRA_HEADERS_PREFIX"
+	EnvOIDCConfigFile                  string = "AUTH_OIDC_CONFIG_FILE"
+	EnvClientCAFile                    string = "AUTH_CLIENT_CA_FILE"
+	EnvAuthConfigFile                  string = "AUTH_CONFIG_FILE"
+
+	EnvAuditPolicyFile    string = "AUDIT_POLICY_FILE"
+	EnvAuditLogPath       string = "AUDIT_LOG_PATH"
+	EnvAuditLogMaxSize    string = "AUDIT_LOG_MAXSIZE"
+	EnvAuditLogMaxBackups string = "AUDIT_LOG_MAXBACKUP"
+	EnvAuditLogMaxAge     string = "AUDIT_LOG_MAXAGE"
+	EnvAuditWebhookURL    string = "AUDIT_WEBHOOK_URL"
 
 	DefaultAddr string = ":8080"
 
+	DefaultRequestHeaderUsernameHeader     string = "X-Remote-User"
+	DefaultRequestHeaderGroupHeader        string = "X-Remote-Group"
+	DefaultRequestHeaderExtraHeadersPrefix string = "X-Remote-Extra-"
+
 	HttpContentType            string = "Content-Type"
 	HttpContentTypeApplication string = "application/json;charset=utf-8"
+
+	UserExtraAuthenticator string = "namespacelister.konflux-ci.dev/authenticator"
+
+	HttpWarning          string = "Warning"
+	HttpWarningStaleData string = `299 - "namespaces are served from a snapshot of the access cache and might be outdated"`
 )
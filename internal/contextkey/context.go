@@ -3,6 +3,10 @@ package contextkey
 type ContextKey string
 
 const (
-	ContextKeyLogger      ContextKey = "logger"
-	ContextKeyUserDetails ContextKey = "user-details"
+	ContextKeyLogger              ContextKey = "logger"
+	ContextKeyUserDetails         ContextKey = "user-details"
+	ContextKeyAccessProfile       ContextKey = "access-profile"
+	ContextKeyImpersonatorDetails ContextKey = "impersonator-details"
+	ContextKeyCorrelationID       ContextKey = "correlation-id"
+	ContextKeyAuditEvent          ContextKey = "audit-event"
 )
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+)
+
+// AddAuditMiddleware records the audit event of authenticated requests as defined by the auditor's policy.
+// The audit event is stored in the request's context for the next handlers to complete it.
+func AddAuditMiddleware(a *audit.Auditor, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		rs, ok := ctx.Value(contextkey.ContextKeyUserDetails).(*authenticator.Response)
+		if !ok || rs == nil || rs.User == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ev := a.NewEvent(rs.User)
+		if ev == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ev.RequestURI = r.URL.RequestURI()
+		ev.Authenticator = authenticatorName(rs)
+		ev.CorrelationID, _ = ctx.Value(contextkey.ContextKeyCorrelationID).(string)
+
+		// serve the request recording the response code
+		m := httpsnoop.CaptureMetrics(next, w, r.WithContext(audit.WithEvent(ctx, ev)))
+		ev.ResponseCode = m.Code
+		a.Process(ctx, ev)
+	}
+}
@@ -0,0 +1,110 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/konflux-ci/namespace-lister/internal/audit"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/middleware"
+)
+
+type auditSink []*audit.Event
+
+func (s *auditSink) Process(_ context.Context, ev *audit.Event) { *s = append(*s, ev) }
+
+var _ = Describe("HttpAuditMiddleware", func() {
+	var (
+		sink    *auditSink
+		auditor *audit.Auditor
+	)
+
+	BeforeEach(func() {
+		sink = &auditSink{}
+		auditor = audit.New(&audit.Policy{Rules: []audit.PolicyRule{
+			{Level: audit.LevelNamespaces, Users: []string{"myuser"}},
+		}}, sink)
+	})
+
+	newRequest := func(ctx context.Context, username string) *http.Request {
+		GinkgoHelper()
+
+		ctx = context.WithValue(ctx, contextkey.ContextKeyCorrelationID, "my-correlation-id")
+		ctx = context.WithValue(ctx, contextkey.ContextKeyUserDetails, &authenticator.Response{
+			User: &user.DefaultInfo{
+				Name:   username,
+				Groups: []string{"mygroup"},
+				Extra:  map[string][]string{constants.UserExtraAuthenticator: {"tokenreview"}},
+			},
+		})
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/namespaces?limit=1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		return r
+	}
+
+	It("records the audit event of the request", func(ctx context.Context) {
+		// given
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			audit.RecordVerb(r.Context(), "list")
+			audit.RecordNamespaces(r.Context(), "3", []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myns"}}})
+			w.WriteHeader(http.StatusOK)
+		})
+
+		// when
+		middleware.AddAuditMiddleware(auditor, next).ServeHTTP(httptest.NewRecorder(), newRequest(ctx, "myuser"))
+
+		// then
+		Expect(*sink).To(HaveExactElements(And(
+			HaveField("CorrelationID", "my-correlation-id"),
+			HaveField("RequestURI", "/api/v1/namespaces?limit=1"),
+			HaveField("Verb", "list"),
+			HaveField("User.Username", "myuser"),
+			HaveField("User.Groups", HaveExactElements("mygroup")),
+			HaveField("Authenticator", "tokenreview"),
+			HaveField("ResponseCode", http.StatusOK),
+			HaveField("Namespaces", HaveExactElements("myns")),
+			HaveField("CacheGeneration", "3"),
+		)))
+	})
+
+	It("records the response code of failed requests", func(ctx context.Context) {
+		// given
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+
+		// when
+		middleware.AddAuditMiddleware(auditor, next).ServeHTTP(httptest.NewRecorder(), newRequest(ctx, "myuser"))
+
+		// then
+		Expect(*sink).To(HaveExactElements(And(
+			HaveField("ResponseCode", http.StatusForbidden),
+			HaveField("NamespaceCount", BeNil()),
+		)))
+	})
+
+	It("does not audit requests the policy excludes", func(ctx context.Context) {
+		// given
+		served := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = true
+			Expect(audit.EventFrom(r.Context())).To(BeNil())
+		})
+
+		// when
+		middleware.AddAuditMiddleware(auditor, next).ServeHTTP(httptest.NewRecorder(), newRequest(ctx, "otheruser"))
+
+		// then
+		Expect(served).To(BeTrue())
+		Expect(*sink).To(BeEmpty())
+	})
+})
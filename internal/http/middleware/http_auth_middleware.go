@@ -4,9 +4,12 @@ import (
 	"context"
 	"net/http"
 
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 
+	"github.com/konflux-ci/namespace-lister/internal/constants"
 	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
 	"github.com/konflux-ci/namespace-lister/internal/log"
 )
 
@@ -17,23 +20,39 @@ func AddAuthnMiddleware(ar authenticator.Request, next http.Handler) http.Handle
 
 		switch {
 		case err != nil: // error contacting the APIServer for authenticating the request
-			w.WriteHeader(http.StatusUnauthorized)
 			l := log.GetLoggerFromContext(r.Context())
 			l.Error("error authenticating request", "error", err)
+			status.Write(l, w, kerrors.NewUnauthorized("Unauthorized").Status())
 			return
 
 		case !ok: // request could not be authenticated
-			w.WriteHeader(http.StatusUnauthorized)
+			status.Write(log.GetLoggerFromContext(r.Context()), w, kerrors.NewUnauthorized("Unauthorized").Status())
 			return
 
 		default: // request is authenticated
 			// Inject authentication details into request context
 			ctx := r.Context()
 			authCtx := context.WithValue(ctx, contextkey.ContextKeyUserDetails, rs)
-			log.GetLoggerFromContext(r.Context()).With("user", rs).Debug("request authenticated")
+			l := log.GetLoggerFromContext(r.Context())
+			if an := authenticatorName(rs); an != "" {
+				l = l.With("authenticator", an)
+				authCtx = log.SetLoggerIntoContext(authCtx, l)
+			}
+			l.With("user", rs).Debug("request authenticated")
 
 			// serve next request
 			next.ServeHTTP(w, r.WithContext(authCtx))
 		}
 	}
 }
+
+// authenticatorName returns the name of the authenticator that authenticated the request, if known
+func authenticatorName(rs *authenticator.Response) string {
+	if rs == nil || rs.User == nil {
+		return ""
+	}
+	if an := rs.User.GetExtra()[constants.UserExtraAuthenticator]; len(an) > 0 {
+		return an[0]
+	}
+	return ""
+}
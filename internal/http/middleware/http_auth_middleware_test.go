@@ -3,6 +3,7 @@ package middleware_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
 )
@@ -50,6 +52,13 @@ var _ = Describe("HttpAuthMiddleware", func() {
 
 		By("check the StatusCode is Unauthorized")
 		Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+
+		By("check the body is an Unauthorized Status")
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Code).To(BeEquivalentTo(http.StatusUnauthorized))
+		Expect(s.Reason).To(Equal(metav1.StatusReasonUnauthorized))
 	})
 
 	It("returns Unauthorized on errored requests", func(ctx context.Context) {
@@ -85,6 +94,13 @@ var _ = Describe("HttpAuthMiddleware", func() {
 		By("check the StatusCode is Unauthorized")
 		Expect(w.Result().StatusCode).To(Equal(http.StatusUnauthorized))
 
+		By("check the body is an Unauthorized Status")
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.Code).To(BeEquivalentTo(http.StatusUnauthorized))
+		Expect(s.Reason).To(Equal(metav1.StatusReasonUnauthorized))
+
 		By("check the error log includes the failure and message")
 		logged := buf.String()
 		Expect(logged).To(And(
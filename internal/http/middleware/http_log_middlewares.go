@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"cmp"
+	"context"
 	"log/slog"
 	"net/http"
 
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
 	"github.com/konflux-ci/namespace-lister/internal/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/util/uuid"
 )
 
@@ -20,8 +24,8 @@ func AddLogRequestMiddleware(next http.Handler) http.HandlerFunc {
 }
 
 // AddLogCorrelationIDMiddleware retrieves the correlation ID from the request's header
-// X-Correlation-ID and adds it to the logs. If the header is not present, it generates
-// a new Correlation-ID.
+// X-Correlation-ID and adds it to the logs and to the request's context.
+// If the header is not present, it generates a new Correlation-ID.
 func AddLogCorrelationIDMiddleware(next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// get logger from context
@@ -31,9 +35,11 @@ func AddLogCorrelationIDMiddleware(next http.Handler) http.HandlerFunc {
 		// If not present, generate a new one.
 		cid := cmp.Or(r.Header.Get("X-Correlation-ID"), string(uuid.NewUUID()))
 		l = l.With("correlation-id", cid)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("namespacelister.correlation_id", cid))
 
 		// run the next handler
 		ctx := log.SetLoggerIntoContext(r.Context(), l)
+		ctx = context.WithValue(ctx, contextkey.ContextKeyCorrelationID, cid)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
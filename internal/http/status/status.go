@@ -0,0 +1,48 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Write writes the provided Status as a JSON encoded reply
+func Write(l *slog.Logger, w http.ResponseWriter, s metav1.Status) {
+	s.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: metav1.SchemeGroupVersion.Version}
+	if s.Status == "" {
+		s.Status = metav1.StatusFailure
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(constants.HttpContentType, constants.HttpContentTypeApplication)
+	w.WriteHeader(int(s.Code))
+	if _, err := w.Write(b); err != nil {
+		l.Error("error writing status reply", "error", err)
+	}
+}
+
+// WriteError writes the error as a JSON encoded Status.
+// If the error does not implement the APIStatus interface, an InternalError Status is written.
+func WriteError(l *slog.Logger, w http.ResponseWriter, err error) {
+	Write(l, w, FromError(err))
+}
+
+// FromError returns the Status for the provided error.
+// If the error does not implement the APIStatus interface, an InternalError Status is returned.
+func FromError(err error) metav1.Status {
+	var s kerrors.APIStatus
+	if errors.As(err, &s) {
+		return s.Status()
+	}
+	return kerrors.NewInternalError(err).Status()
+}
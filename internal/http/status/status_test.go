@@ -0,0 +1,47 @@
+package status_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/internal/http/status"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Status", func() {
+	DescribeTable("writes errors as Status", func(err error, expectedCode int, expectedReason metav1.StatusReason) {
+		// given
+		w := httptest.NewRecorder()
+
+		// when
+		status.WriteError(slog.Default(), w, err)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(expectedCode))
+		Expect(w.Result().Header.Get(constants.HttpContentType)).To(Equal(constants.HttpContentTypeApplication))
+
+		s := metav1.Status{}
+		Expect(json.NewDecoder(w.Result().Body).Decode(&s)).To(Succeed())
+		Expect(s.Kind).To(Equal("Status"))
+		Expect(s.APIVersion).To(Equal("v1"))
+		Expect(s.Status).To(Equal(metav1.StatusFailure))
+		Expect(s.Code).To(BeEquivalentTo(expectedCode))
+		Expect(s.Reason).To(Equal(expectedReason))
+		Expect(s.Message).To(ContainSubstring(err.Error()))
+	},
+		Entry("unhandled error", errors.New("unhandled error"), http.StatusInternalServerError, metav1.StatusReasonInternalError),
+		Entry("api status error", kerrors.NewTimeoutError("timed-out", 200), http.StatusGatewayTimeout, metav1.StatusReasonTimeout),
+		Entry("wrapped api status error",
+			errors.Join(kerrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "myns")),
+			http.StatusNotFound, metav1.StatusReasonNotFound),
+	)
+})
@@ -3,12 +3,14 @@ package resourcecache
 import (
 	"context"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/konflux-ci/namespace-lister/internal/constants"
 	"github.com/konflux-ci/namespace-lister/internal/log"
 	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func BuildAndRegisterAccessCacheMetrics(registry prometheus.Registerer) (cache.AccessCacheMetrics, error) {
@@ -26,19 +28,107 @@ func BuildAndRegisterAccessCacheMetrics(registry prometheus.Registerer) (cache.A
 // GetValidResyncPeriodFromEnvOrZero retrieves AccessCache's ResyncPeriod from environment variables.
 // If the environment variable is not set or the value is not a valid non-negative duration, it returns the zero value.
 func GetValidResyncPeriodFromEnvOrZero(ctx context.Context) time.Duration {
+	return getValidDurationFromEnvOrZero(ctx, constants.EnvCacheResyncPeriod)
+}
+
+// GetValidStalenessThresholdFromEnvOrZero retrieves the maximum age of AccessCache's data
+// the server is considered ready with from environment variables.
+// If the environment variable is not set or the value is not a valid non-negative duration, it returns the zero value.
+func GetValidStalenessThresholdFromEnvOrZero(ctx context.Context) time.Duration {
+	return getValidDurationFromEnvOrZero(ctx, constants.EnvCacheStalenessThreshold)
+}
+
+// GetValidSnapshotMaxAgeFromEnvOrZero retrieves from environment variables
+// the maximum age of the snapshots AccessCache is restored from.
+// If the environment variable is not set or the value is not a valid non-negative duration, it returns the zero value.
+func GetValidSnapshotMaxAgeFromEnvOrZero(ctx context.Context) time.Duration {
+	return getValidDurationFromEnvOrZero(ctx, constants.EnvCacheSnapshotMaxAge)
+}
+
+// GetSnapshotReadyFromEnv retrieves whether the server is considered ready with AccessCache's data
+// restored from a snapshot, before its first synchronization, from environment variables.
+// If the environment variable is not set or the value is not a valid boolean, it returns false.
+func GetSnapshotReadyFromEnv(ctx context.Context) bool {
+	v, ok := os.LookupEnv(constants.EnvCacheSnapshotReady)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.GetLoggerFromContext(ctx).Warn("can not parse boolean from environment variable", "variable", constants.EnvCacheSnapshotReady, "error", err)
+		return false
+	}
+	return b
+}
+
+func getValidDurationFromEnvOrZero(ctx context.Context, env string) time.Duration {
 	var zero time.Duration
-	rps, ok := os.LookupEnv(constants.EnvCacheResyncPeriod)
+	ds, ok := os.LookupEnv(env)
 	if !ok {
 		return zero
 	}
-	rp, err := time.ParseDuration(rps)
+	d, err := time.ParseDuration(ds)
 	if err != nil {
-		log.GetLoggerFromContext(ctx).Warn("can not parse duration from environment variable", "error", err)
+		log.GetLoggerFromContext(ctx).Warn("can not parse duration from environment variable", "variable", env, "error", err)
 		return zero
 	}
-	if rp < 0 {
-		log.GetLoggerFromContext(ctx).Warn("negative resync period, using zero", "value", rps)
+	if d < 0 {
+		log.GetLoggerFromContext(ctx).Warn("negative duration from environment variable, using zero", "variable", env, "value", ds)
 		return zero
 	}
-	return rp
+	return d
+}
+
+// GetValidConcurrencyFromEnvOrZero retrieves AccessCache's synchronization Concurrency from environment variables.
+// If the environment variable is not set or the value is not a valid positive integer, it returns zero.
+func GetValidConcurrencyFromEnvOrZero(ctx context.Context) int {
+	cs, ok := os.LookupEnv(constants.EnvCacheSynchConcurrency)
+	if !ok {
+		return 0
+	}
+	c, err := strconv.Atoi(cs)
+	if err != nil {
+		log.GetLoggerFromContext(ctx).Warn("can not parse integer from environment variable", "error", err)
+		return 0
+	}
+	if c <= 0 {
+		log.GetLoggerFromContext(ctx).Warn("non-positive concurrency, using zero", "value", cs)
+		return 0
+	}
+	return c
+}
+
+// GetSnapshotStoreFromEnvOrNil builds a FileSnapshotStore persisting the snapshots of
+// the AccessCache of the given access profile at the path retrieved from environment variables.
+// The snapshots of profiles other than the default one are persisted at the path suffixed by the profile's name.
+// If the environment variable is not set, it returns nil.
+func GetSnapshotStoreFromEnvOrNil(profile string) cache.SnapshotStore {
+	p, ok := os.LookupEnv(constants.EnvCacheSnapshotPath)
+	if !ok || p == "" {
+		return nil
+	}
+	if profile != DefaultAccessProfileName {
+		p += "." + profile
+	}
+	return cache.NewFileSnapshotStore(p)
+}
+
+// GetAccessAttributesFromEnv retrieves the action subjects need to be allowed to perform
+// in a namespace to access it from environment variables.
+// The resource is expected in the `resource.group` form, e.g. `pipelineruns.tekton.dev`.
+// Values not set in environment variables are defaulted to `get namespaces`.
+func GetAccessAttributesFromEnv() (cache.AccessAttributes, error) {
+	aa := cache.DefaultAccessAttributes
+	if v, ok := os.LookupEnv(constants.EnvAccessVerb); ok {
+		aa.Verb = v
+	}
+	if v, ok := os.LookupEnv(constants.EnvAccessResource); ok {
+		gr := schema.ParseGroupResource(v)
+		aa.APIGroup, aa.Resource = gr.Group, gr.Resource
+	}
+
+	if err := aa.Validate(); err != nil {
+		return cache.AccessAttributes{}, err
+	}
+	return aa, nil
 }
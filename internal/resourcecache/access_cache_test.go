@@ -3,14 +3,17 @@ package resourcecache_test
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/ptr"
 
 	"github.com/konflux-ci/namespace-lister/internal/constants"
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 )
 
 var _ = Describe("Access Cache", func() {
@@ -89,4 +92,196 @@ var _ = Describe("Access Cache", func() {
 			Entry("negative duration returns zero", "-1h", time.Duration(0)),
 		)
 	})
+
+	Describe("GetValidConcurrencyFromEnvOrZero", Serial, func() {
+		It("returns zero when env is not set", func(ctx context.Context) {
+			// given
+			if v, ok := os.LookupEnv(constants.EnvCacheSynchConcurrency); ok {
+				Expect(os.Unsetenv(constants.EnvCacheSynchConcurrency)).To(Succeed()) //nolint:usetesting
+				defer os.Setenv(constants.EnvCacheSynchConcurrency, v)                //nolint:usetesting
+			}
+
+			// when
+			c := resourcecache.GetValidConcurrencyFromEnvOrZero(ctx)
+
+			// then
+			Expect(c).To(BeZero())
+		})
+
+		DescribeTable("parses env value",
+			func(ctx context.Context, envValue string, expected int) {
+				// given
+				GinkgoT().Setenv(constants.EnvCacheSynchConcurrency, envValue)
+
+				// when
+				c := resourcecache.GetValidConcurrencyFromEnvOrZero(ctx)
+
+				// then
+				Expect(c).To(Equal(expected))
+			},
+			Entry("valid concurrency 1", "1", 1),
+			Entry("valid concurrency 16", "16", 16),
+			Entry("invalid concurrency returns zero", "not-a-number", 0),
+			Entry("zero concurrency returns zero", "0", 0),
+			Entry("negative concurrency returns zero", "-4", 0),
+		)
+	})
+
+	Describe("GetSnapshotStoreFromEnvOrNil", Serial, func() {
+		It("returns nil when env is not set", func() {
+			// given
+			if v, ok := os.LookupEnv(constants.EnvCacheSnapshotPath); ok {
+				Expect(os.Unsetenv(constants.EnvCacheSnapshotPath)).To(Succeed()) //nolint:usetesting
+				defer os.Setenv(constants.EnvCacheSnapshotPath, v)                //nolint:usetesting
+			}
+
+			// when
+			s := resourcecache.GetSnapshotStoreFromEnvOrNil(resourcecache.DefaultAccessProfileName)
+
+			// then
+			Expect(s).To(BeNil())
+		})
+
+		It("returns a file store when env is set", func() {
+			// given
+			GinkgoT().Setenv(constants.EnvCacheSnapshotPath, filepath.Join(GinkgoT().TempDir(), "snapshot.json"))
+
+			// when
+			s := resourcecache.GetSnapshotStoreFromEnvOrNil(resourcecache.DefaultAccessProfileName)
+
+			// then
+			Expect(s).NotTo(BeNil())
+			Expect(s.Load()).To(BeNil())
+		})
+	})
+
+	Describe("GetValidStalenessThresholdFromEnvOrZero", Serial, func() {
+		It("returns zero when env is not set", func(ctx context.Context) {
+			// given
+			if v, ok := os.LookupEnv(constants.EnvCacheStalenessThreshold); ok {
+				Expect(os.Unsetenv(constants.EnvCacheStalenessThreshold)).To(Succeed()) //nolint:usetesting
+				defer os.Setenv(constants.EnvCacheStalenessThreshold, v)                //nolint:usetesting
+			}
+
+			// when
+			d := resourcecache.GetValidStalenessThresholdFromEnvOrZero(ctx)
+
+			// then
+			Expect(d).To(Equal(time.Duration(0)))
+		})
+
+		DescribeTable("parses env value",
+			func(ctx context.Context, envValue string, expected time.Duration) {
+				// given
+				GinkgoT().Setenv(constants.EnvCacheStalenessThreshold, envValue)
+
+				// when
+				d := resourcecache.GetValidStalenessThresholdFromEnvOrZero(ctx)
+
+				// then
+				Expect(d).To(Equal(expected))
+			},
+			Entry("valid duration 30m", "30m", 30*time.Minute),
+			Entry("invalid duration returns zero", "not-a-duration", time.Duration(0)),
+			Entry("negative duration returns zero", "-1h", time.Duration(0)),
+		)
+	})
+
+	Describe("GetAccessAttributesFromEnv", Serial, func() {
+		DescribeTable("parses env values",
+			func(verb, resource *string, expected cache.AccessAttributes) {
+				// given
+				for k, v := range map[string]*string{constants.EnvAccessVerb: verb, constants.EnvAccessResource: resource} {
+					if v != nil {
+						GinkgoT().Setenv(k, *v)
+						continue
+					}
+					if ov, ok := os.LookupEnv(k); ok {
+						Expect(os.Unsetenv(k)).To(Succeed()) //nolint:usetesting
+						DeferCleanup(os.Setenv, k, ov)
+					}
+				}
+
+				// when
+				aa, err := resourcecache.GetAccessAttributesFromEnv()
+
+				// then
+				Expect(err).NotTo(HaveOccurred())
+				Expect(aa).To(Equal(expected))
+			},
+			Entry("defaults to get namespaces", nil, nil, cache.DefaultAccessAttributes),
+			Entry("core resource", ptr.To("list"), ptr.To("pods"),
+				cache.AccessAttributes{Verb: "list", Resource: "pods"}),
+			Entry("grouped resource", ptr.To("create"), ptr.To("applications.appstudio.redhat.com"),
+				cache.AccessAttributes{Verb: "create", APIGroup: "appstudio.redhat.com", Resource: "applications"}),
+			Entry("verb only", ptr.To("list"), nil,
+				cache.AccessAttributes{Verb: "list", Resource: "namespaces"}),
+		)
+
+		It("returns an error for empty values", func() {
+			// given
+			GinkgoT().Setenv(constants.EnvAccessVerb, "")
+
+			// when
+			_, err := resourcecache.GetAccessAttributesFromEnv()
+
+			// then
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("GetAccessProfilesFromEnv", Serial, func() {
+		BeforeEach(func() {
+			for _, k := range []string{constants.EnvAccessVerb, constants.EnvAccessResource, constants.EnvAccessProfiles} {
+				if ov, ok := os.LookupEnv(k); ok {
+					Expect(os.Unsetenv(k)).To(Succeed()) //nolint:usetesting
+					DeferCleanup(os.Setenv, k, ov)
+				}
+			}
+		})
+
+		It("returns the default profile only when env is not set", func() {
+			// when
+			pp, err := resourcecache.GetAccessProfilesFromEnv()
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pp).To(Equal([]resourcecache.AccessProfile{
+				{Name: resourcecache.DefaultAccessProfileName, AccessAttributes: cache.DefaultAccessAttributes},
+			}))
+		})
+
+		It("parses additional profiles", func() {
+			// given
+			GinkgoT().Setenv(constants.EnvAccessProfiles, "viewer=get namespaces; contributor=create pipelineruns.tekton.dev;")
+
+			// when
+			pp, err := resourcecache.GetAccessProfilesFromEnv()
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pp).To(Equal([]resourcecache.AccessProfile{
+				{Name: resourcecache.DefaultAccessProfileName, AccessAttributes: cache.DefaultAccessAttributes},
+				{Name: "viewer", AccessAttributes: cache.DefaultAccessAttributes},
+				{Name: "contributor", AccessAttributes: cache.AccessAttributes{Verb: "create", APIGroup: "tekton.dev", Resource: "pipelineruns"}},
+			}))
+		})
+
+		DescribeTable("returns an error for invalid profiles", func(value string) {
+			// given
+			GinkgoT().Setenv(constants.EnvAccessProfiles, value)
+
+			// when
+			_, err := resourcecache.GetAccessProfilesFromEnv()
+
+			// then
+			Expect(err).To(HaveOccurred())
+		},
+			Entry("missing name", "get namespaces"),
+			Entry("invalid name", "My_Profile=get namespaces"),
+			Entry("missing resource", "viewer=get"),
+			Entry("duplicated name", "viewer=get namespaces;viewer=list namespaces"),
+			Entry("default name", "default=list namespaces"),
+		)
+	})
 })
@@ -0,0 +1,80 @@
+package resourcecache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultAccessProfileName is the name of the profile serving requests not selecting any
+const DefaultAccessProfileName string = "default"
+
+// AccessProfile names the AccessAttributes namespaces are evaluated against
+type AccessProfile struct {
+	Name             string
+	AccessAttributes cache.AccessAttributes
+}
+
+// GetAccessProfilesFromEnv retrieves the access profiles from environment variables.
+// The first profile is always the default one, built from the access verb and resource.
+// Additional profiles are read as a semicolon separated list of `name=verb resource.group` items,
+// e.g. `viewer=get namespaces;contributor=create pipelineruns.tekton.dev`.
+func GetAccessProfilesFromEnv() ([]AccessProfile, error) {
+	aa, err := GetAccessAttributesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	pp := []AccessProfile{{Name: DefaultAccessProfileName, AccessAttributes: aa}}
+
+	v, ok := os.LookupEnv(constants.EnvAccessProfiles)
+	if !ok {
+		return pp, nil
+	}
+
+	for item := range strings.SplitSeq(v, ";") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		p, err := parseAccessProfile(item)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range pp {
+			if ep.Name == p.Name {
+				return nil, fmt.Errorf("invalid access profile %q: duplicated name", item)
+			}
+		}
+		pp = append(pp, *p)
+	}
+	return pp, nil
+}
+
+// parseAccessProfile parses an access profile in the `name=verb resource.group` form
+func parseAccessProfile(s string) (*AccessProfile, error) {
+	name, attrs, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid access profile %q: expected the name=verb resource.group form", s)
+	}
+	name = strings.TrimSpace(name)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid access profile %q: invalid name: %s", s, strings.Join(errs, ", "))
+	}
+
+	ff := strings.Fields(attrs)
+	if len(ff) != 2 {
+		return nil, fmt.Errorf("invalid access profile %q: expected the name=verb resource.group form", s)
+	}
+	gr := schema.ParseGroupResource(ff[1])
+	aa := cache.AccessAttributes{Verb: ff[0], APIGroup: gr.Group, Resource: gr.Resource}
+	if err := aa.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid access profile %q: %w", s, err)
+	}
+
+	return &AccessProfile{Name: name, AccessAttributes: aa}, nil
+}
@@ -10,17 +10,28 @@ import (
 
 // BuildAndStart builds and starts a resource Cache.
 func BuildAndStart(ctx context.Context, cfg *Config) (cache.Cache, error) {
-	c, err := build(cfg)
+	c, err := Build(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := start(ctx, c); err != nil {
+	if err := Start(ctx, c); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// Build builds a resource Cache.
+// The cache is meant to be started via the `Start` function.
+func Build(cfg *Config) (cache.Cache, error) {
+	return build(cfg)
+}
+
+// Start starts the resource Cache and waits for it to be synced.
+func Start(ctx context.Context, c cache.Cache) error {
+	return start(ctx, c)
+}
+
 func start(ctx context.Context, c cache.Cache) error {
 	// get informers
 	for _, o := range cachedObjects {
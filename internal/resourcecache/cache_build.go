@@ -4,6 +4,7 @@ import (
 	"cmp"
 
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache/internal/transform"
+	authcache "github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -34,7 +35,11 @@ func build(cfg *Config) (cache.Cache, error) {
 	}
 
 	// build embedded cache options
-	o := buildCacheOptions(s, cfg.NamespacesLabelSelector)
+	aa := cfg.AccessAttributes
+	if len(aa) == 0 {
+		aa = []authcache.AccessAttributes{authcache.DefaultAccessAttributes}
+	}
+	o := buildCacheOptions(s, cfg.NamespacesLabelSelector, aa)
 
 	// build cache
 	return cache.New(cfg.RestConfig, o)
@@ -52,29 +57,29 @@ func buildScheme() (*runtime.Scheme, error) {
 	return s, nil
 }
 
-func buildCacheOptions(s *runtime.Scheme, namespaceSelector labels.Selector) cache.Options {
+func buildCacheOptions(s *runtime.Scheme, namespaceSelector labels.Selector, accessAttributes []authcache.AccessAttributes) cache.Options {
 	return cache.Options{
 		Scheme:                       s,
 		DefaultUnsafeDisableDeepCopy: ptr.To(true),
 		ReaderFailOnMissingInformer:  true,
-		ByObject:                     byObjectTransformers(namespaceSelector),
+		ByObject:                     byObjectTransformers(namespaceSelector, accessAttributes),
 	}
 }
 
-func byObjectTransformers(namespaceSelector labels.Selector) map[client.Object]cache.ByObject {
+func byObjectTransformers(namespaceSelector labels.Selector, accessAttributes []authcache.AccessAttributes) map[client.Object]cache.ByObject {
 	return map[client.Object]cache.ByObject{
 		&corev1.Namespace{}: {
 			Label:     namespaceSelector,
 			Transform: transform.TrimNamespace(),
 		},
 		&rbacv1.Role{}: {
-			Transform: transform.TrimRole(),
+			Transform: transform.TrimRoleForAccess(accessAttributes...),
 		},
 		&rbacv1.RoleBinding{}: {
 			Transform: transform.TrimRoleBinding(),
 		},
 		&rbacv1.ClusterRole{}: {
-			Transform: transform.TrimClusterRole(),
+			Transform: transform.TrimClusterRoleForAccess(accessAttributes...),
 		},
 		&rbacv1.ClusterRoleBinding{}: {
 			Transform: transform.TrimClusterRoleBinding(),
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 )
@@ -16,6 +17,8 @@ var ErrResourceCacheConfig error = errors.New("error building resource cache con
 type Config struct {
 	RestConfig              *rest.Config
 	NamespacesLabelSelector labels.Selector
+	// AccessAttributes selects the policy rules to cache, defaults to `get namespaces`
+	AccessAttributes []cache.AccessAttributes
 }
 
 func NewConfigFromEnv(cfg *rest.Config) (*Config, error) {
@@ -25,6 +28,15 @@ func NewConfigFromEnv(cfg *rest.Config) (*Config, error) {
 		return nil, err
 	}
 
+	// get access attributes of all the access profiles
+	pp, err := GetAccessProfilesFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrResourceCacheConfig, err)
+	}
+	for _, p := range pp {
+		cacheCfg.AccessAttributes = append(cacheCfg.AccessAttributes, p.AccessAttributes)
+	}
+
 	return cacheCfg, nil
 }
 
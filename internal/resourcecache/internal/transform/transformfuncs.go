@@ -2,12 +2,13 @@ package transform
 
 import (
 	"fmt"
-	"slices"
 
+	authcache "github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 )
 
@@ -35,6 +36,11 @@ func TrimAnnotations() toolscache.TransformFunc {
 }
 
 func TrimRole() toolscache.TransformFunc {
+	return TrimRoleForAccess(authcache.DefaultAccessAttributes)
+}
+
+// TrimRoleForAccess trims the Role keeping only the policy rules granting any of the accesses
+func TrimRoleForAccess(aa ...authcache.AccessAttributes) toolscache.TransformFunc {
 	return MergeTransformFunc(
 		cache.TransformStripManagedFields(),
 		TrimAnnotations(),
@@ -44,7 +50,7 @@ func TrimRole() toolscache.TransformFunc {
 				return nil, fmt.Errorf("error caching Role: expected Role received %T", i)
 			}
 
-			r.Rules = filterNamespacesRelatedPolicyRules(r.Rules)
+			r.Rules = filterAccessRelatedPolicyRules(r.Rules, aa)
 			if len(r.Rules) == 0 {
 				return nil, nil
 			}
@@ -54,6 +60,11 @@ func TrimRole() toolscache.TransformFunc {
 }
 
 func TrimClusterRole() toolscache.TransformFunc {
+	return TrimClusterRoleForAccess(authcache.DefaultAccessAttributes)
+}
+
+// TrimClusterRoleForAccess trims the ClusterRole keeping only the policy rules granting any of the accesses
+func TrimClusterRoleForAccess(aa ...authcache.AccessAttributes) toolscache.TransformFunc {
 	return MergeTransformFunc(
 		cache.TransformStripManagedFields(),
 		TrimAnnotations(),
@@ -63,7 +74,7 @@ func TrimClusterRole() toolscache.TransformFunc {
 				return nil, fmt.Errorf("error caching ClusterRole: expected a ClusterRole received %T", i)
 			}
 
-			cr.Rules = filterNamespacesRelatedPolicyRules(cr.Rules)
+			cr.Rules = filterAccessRelatedPolicyRules(cr.Rules, aa)
 			if len(cr.Rules) == 0 {
 				return nil, nil
 			}
@@ -82,7 +93,8 @@ func TrimNamespace() toolscache.TransformFunc {
 			}
 
 			ns.Spec = corev1.NamespaceSpec{}
-			ns.Status = corev1.NamespaceStatus{}
+			// the phase is kept as it is shown to the users
+			ns.Status = corev1.NamespaceStatus{Phase: ns.Status.Phase}
 			return ns, nil
 		})
 }
@@ -96,16 +108,23 @@ func trimBinding() toolscache.TransformFunc {
 	return MergeTransformFunc(cache.TransformStripManagedFields(), TrimAnnotations())
 }
 
-func filterNamespacesRelatedPolicyRules(pp []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+// filterAccessRelatedPolicyRules keeps the rules granting any of the accesses,
+// reducing them to one rule per granted access's verb, API group, and resource.
+// Rules are matched as the Kubernetes RBAC does, wildcards included.
+// As the name of the resource is not known in advance, resource names are not matched but preserved.
+func filterAccessRelatedPolicyRules(pp []rbacv1.PolicyRule, aa []authcache.AccessAttributes) []rbacv1.PolicyRule {
 	var fr []rbacv1.PolicyRule
 	for _, r := range pp {
-		if slices.Contains(r.APIGroups, "") &&
-			slices.Contains(r.Resources, "namespaces") &&
-			slices.Contains(r.Verbs, "get") {
-			r.APIGroups = []string{""}
-			r.Resources = []string{"namespaces"}
-			r.Verbs = []string{"get"}
-			fr = append(fr, r)
+		mr := r
+		mr.ResourceNames = nil
+		for _, a := range aa {
+			if ra := a.AttributesRecordFor(""); rbac.RuleAllows(&ra, &mr) {
+				ar := r
+				ar.APIGroups = []string{a.APIGroup}
+				ar.Resources = []string{a.Resource}
+				ar.Verbs = []string{a.Verb}
+				fr = append(fr, ar)
+			}
 		}
 	}
 	return fr
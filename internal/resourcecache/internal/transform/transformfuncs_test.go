@@ -14,6 +14,7 @@ import (
 
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache/internal/transform"
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache/internal/transform/mocks"
+	authcache "github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 )
 
 var managedFields = []metav1.ManagedFieldsEntry{{
@@ -232,6 +233,78 @@ var _ = Describe("Transform Functions", func() {
 					Resources: []string{"namespaces"},
 					Verbs:     []string{"get"},
 				}}),
+			Entry("normalizes rule granting via wildcard verbs",
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "verbs-admin", Namespace: "default"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"namespaces"},
+						Verbs:     []string{"*"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting via wildcard resources",
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "resources-reader", Namespace: "default"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"*"},
+						Verbs:     []string{"get", "list"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting via wildcard API groups",
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "groups-reader", Namespace: "default"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"*"},
+						Resources: []string{"namespaces"},
+						Verbs:     []string{"get"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting everything",
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "default"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"*"},
+						Resources: []string{"*"},
+						Verbs:     []string{"*"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("preserves resource names",
+				&rbacv1.Role{
+					ObjectMeta: metav1.ObjectMeta{Name: "named-reader", Namespace: "default"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups:     []string{""},
+						Resources:     []string{"namespaces"},
+						Verbs:         []string{"get"},
+						ResourceNames: []string{"myns"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups:     []string{""},
+					Resources:     []string{"namespaces"},
+					Verbs:         []string{"get"},
+					ResourceNames: []string{"myns"},
+				}}),
 			Entry("preserves multiple matching rules",
 				&rbacv1.Role{
 					ObjectMeta: metav1.ObjectMeta{Name: "multi-match", Namespace: "default"},
@@ -256,13 +329,21 @@ var _ = Describe("Transform Functions", func() {
 				[]rbacv1.PolicyRule{{
 					APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"},
 				}}),
-			Entry("wildcard resources do not match",
+			Entry("wildcard resources of another API group",
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"get"},
+				}}),
+			Entry("wildcard verbs on other resources",
 				[]rbacv1.PolicyRule{{
-					APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get"},
+					APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"},
 				}}),
-			Entry("wildcard verbs do not match",
+			Entry("namespaces subresources only",
 				[]rbacv1.PolicyRule{{
-					APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"*"},
+					APIGroups: []string{""}, Resources: []string{"namespaces/status"}, Verbs: []string{"get"},
+				}}),
+			Entry("non-resource URLs only",
+				[]rbacv1.PolicyRule{{
+					NonResourceURLs: []string{"*"}, Verbs: []string{"*"},
 				}}),
 			Entry("wrong API group",
 				[]rbacv1.PolicyRule{{
@@ -279,6 +360,71 @@ var _ = Describe("Transform Functions", func() {
 		})
 	})
 
+	Describe("TrimRoleForAccess", func() {
+		aa := authcache.AccessAttributes{Verb: "create", APIGroup: "appstudio.redhat.com", Resource: "applications"}
+
+		It("keeps and normalizes the rules granting the access", func() {
+			result, err := transform.TrimRoleForAccess(aa)(&rbacv1.Role{
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}},
+					{APIGroups: []string{"appstudio.redhat.com"}, Resources: []string{"applications", "components"}, Verbs: []string{"get", "create"}},
+				},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.(*rbacv1.Role).Rules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{"appstudio.redhat.com"}, Resources: []string{"applications"}, Verbs: []string{"create"}},
+			}))
+		})
+
+		It("returns nil for Roles without rules granting the access", func() {
+			result, err := transform.TrimRoleForAccess(aa)(&rbacv1.Role{
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}},
+					{APIGroups: []string{"appstudio.redhat.com"}, Resources: []string{"applications"}, Verbs: []string{"get"}},
+				},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeNil())
+		})
+	})
+
+	Describe("TrimClusterRoleForAccess", func() {
+		It("keeps and normalizes the rules granting the access", func() {
+			aa := authcache.AccessAttributes{Verb: "list", APIGroup: "tekton.dev", Resource: "pipelineruns"}
+			result, err := transform.TrimClusterRoleForAccess(aa)(&rbacv1.ClusterRole{
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{"tekton.dev"}, Resources: []string{"pipelineruns", "taskruns"}, Verbs: []string{"get", "list", "watch"}},
+				},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.(*rbacv1.ClusterRole).Rules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{"tekton.dev"}, Resources: []string{"pipelineruns"}, Verbs: []string{"list"}},
+			}))
+		})
+
+		It("keeps the rules granting any of the accesses", func() {
+			result, err := transform.TrimClusterRoleForAccess(
+				authcache.DefaultAccessAttributes,
+				authcache.AccessAttributes{Verb: "create", APIGroup: "tekton.dev", Resource: "pipelineruns"},
+			)(&rbacv1.ClusterRole{
+				Rules: []rbacv1.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list"}},
+					{APIGroups: []string{"tekton.dev"}, Resources: []string{"pipelineruns", "taskruns"}, Verbs: []string{"*", "create"}},
+					{APIGroups: []string{"tekton.dev"}, Resources: []string{"taskruns"}, Verbs: []string{"create"}},
+				},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.(*rbacv1.ClusterRole).Rules).To(Equal([]rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"tekton.dev"}, Resources: []string{"pipelineruns"}, Verbs: []string{"create"}},
+			}))
+		})
+	})
+
 	Describe("TrimClusterRole", func() {
 		DescribeTable("strips annotations, managed fields, and non-namespace rules",
 			func(cr *rbacv1.ClusterRole, expectedRules []rbacv1.PolicyRule) {
@@ -363,6 +509,78 @@ var _ = Describe("Transform Functions", func() {
 					Resources: []string{"namespaces"},
 					Verbs:     []string{"get"},
 				}}),
+			Entry("normalizes rule granting via wildcard verbs",
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "verbs-admin"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"namespaces"},
+						Verbs:     []string{"*"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting via wildcard resources",
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "resources-reader"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"*"},
+						Verbs:     []string{"get", "list"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting via wildcard API groups",
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "groups-reader"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"*"},
+						Resources: []string{"namespaces"},
+						Verbs:     []string{"get"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("normalizes rule granting everything",
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{"*"},
+						Resources: []string{"*"},
+						Verbs:     []string{"*"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get"},
+				}}),
+			Entry("preserves resource names",
+				&rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{Name: "named-reader"},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups:     []string{""},
+						Resources:     []string{"namespaces"},
+						Verbs:         []string{"get"},
+						ResourceNames: []string{"myns"},
+					}},
+				},
+				[]rbacv1.PolicyRule{{
+					APIGroups:     []string{""},
+					Resources:     []string{"namespaces"},
+					Verbs:         []string{"get"},
+					ResourceNames: []string{"myns"},
+				}}),
 		)
 
 		DescribeTable("returns nil for ClusterRoles without namespace-get rules",
@@ -375,13 +593,21 @@ var _ = Describe("Transform Functions", func() {
 				[]rbacv1.PolicyRule{{
 					APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"},
 				}}),
-			Entry("wildcard resources do not match",
+			Entry("wildcard resources of another API group",
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"get"},
+				}}),
+			Entry("wildcard verbs on other resources",
+				[]rbacv1.PolicyRule{{
+					APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"},
+				}}),
+			Entry("namespaces subresources only",
 				[]rbacv1.PolicyRule{{
-					APIGroups: []string{""}, Resources: []string{"*"}, Verbs: []string{"get"},
+					APIGroups: []string{""}, Resources: []string{"namespaces/status"}, Verbs: []string{"get"},
 				}}),
-			Entry("wildcard verbs do not match",
+			Entry("non-resource URLs only",
 				[]rbacv1.PolicyRule{{
-					APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"*"},
+					NonResourceURLs: []string{"*"}, Verbs: []string{"*"},
 				}}),
 			Entry("wrong API group",
 				[]rbacv1.PolicyRule{{
@@ -399,7 +625,7 @@ var _ = Describe("Transform Functions", func() {
 	})
 
 	Describe("TrimNamespace", func() {
-		It("strips managed fields, spec, and status", func() {
+		It("strips managed fields, spec, and status conditions", func() {
 			// given
 			ns := &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
@@ -409,7 +635,10 @@ var _ = Describe("Transform Functions", func() {
 					ManagedFields: managedFields,
 				},
 				Spec:   corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
-				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+				Status: corev1.NamespaceStatus{
+					Phase:      corev1.NamespaceActive,
+					Conditions: []corev1.NamespaceCondition{{Type: corev1.NamespaceDeletionContentFailure}},
+				},
 			}
 
 			// when
@@ -421,7 +650,7 @@ var _ = Describe("Transform Functions", func() {
 			out := result.(*corev1.Namespace)
 			Expect(out.ManagedFields).To(BeEmpty())
 			Expect(out.Spec).To(BeEquivalentTo(corev1.NamespaceSpec{}))
-			Expect(out.Status).To(BeEquivalentTo(corev1.NamespaceStatus{}))
+			Expect(out.Status).To(BeEquivalentTo(corev1.NamespaceStatus{Phase: corev1.NamespaceActive}))
 
 			// TrimNamespace intentionally does NOT strip annotations, unlike TrimRole/TrimClusterRole
 			By("ensuring annotations are preserved")
@@ -432,7 +661,7 @@ var _ = Describe("Transform Functions", func() {
 				WithTransform(func(ns *corev1.Namespace) *corev1.Namespace {
 					ns.ManagedFields = nil
 					ns.Spec = corev1.NamespaceSpec{}
-					ns.Status = corev1.NamespaceStatus{}
+					ns.Status = corev1.NamespaceStatus{Phase: ns.Status.Phase}
 					return ns
 				}, BeEquivalentTo(out)))
 		})
@@ -0,0 +1,86 @@
+package tracing_test
+
+import (
+	"context"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	collectortracev1 "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+
+	"github.com/konflux-ci/namespace-lister/internal/tracing"
+)
+
+// collector is a stand-in for an OTLP collector, storing the names of the spans it receives
+type collector struct {
+	collectortracev1.UnimplementedTraceServiceServer
+
+	spans chan string
+}
+
+func (c *collector) Export(_ context.Context, req *collectortracev1.ExportTraceServiceRequest) (*collectortracev1.ExportTraceServiceResponse, error) {
+	for _, rs := range req.GetResourceSpans() {
+		for _, a := range rs.GetResource().GetAttributes() {
+			if a.GetKey() == "service.name" {
+				c.spans <- "service.name=" + a.GetValue().GetStringValue()
+			}
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, s := range ss.GetSpans() {
+				c.spans <- s.GetName()
+			}
+		}
+	}
+	return &collectortracev1.ExportTraceServiceResponse{}, nil
+}
+
+var _ = Describe("Setup", func() {
+	var c *collector
+
+	BeforeEach(func() {
+		c = &collector{spans: make(chan string, 10)}
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		srv := grpc.NewServer()
+		collectortracev1.RegisterTraceServiceServer(srv, c)
+		go func() { _ = srv.Serve(lis) }()
+		DeferCleanup(srv.Stop)
+
+		GinkgoT().Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://"+lis.Addr().String())
+		GinkgoT().Setenv("OTEL_EXPORTER_OTLP_INSECURE", "true")
+
+		tp, p := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+		DeferCleanup(func() {
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(p)
+		})
+	})
+
+	It("exports spans via OTLP", func(ctx context.Context) {
+		// given
+		shutdown, err := tracing.Setup(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		// when
+		_, span := otel.Tracer("test").Start(ctx, "my-span")
+		span.End()
+		Expect(shutdown(ctx)).To(Succeed())
+
+		// then
+		Eventually(c.spans).Should(Receive(Equal("service.name=" + tracing.DefaultServiceName)))
+		Eventually(c.spans).Should(Receive(Equal("my-span")))
+	})
+
+	It("propagates the W3C Trace Context", func(ctx context.Context) {
+		// when
+		shutdown, err := tracing.Setup(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(shutdown)
+
+		// then
+		Expect(otel.GetTextMapPropagator().Fields()).To(ContainElement("traceparent"))
+	})
+})
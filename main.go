@@ -3,23 +3,29 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"github.com/konflux-ci/namespace-lister/internal/audit"
 	nslog "github.com/konflux-ci/namespace-lister/internal/log"
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/internal/tracing"
 )
 
 func main() {
@@ -46,6 +52,13 @@ func loadTLSCert(l *slog.Logger, certPath, keyPath string) func(*tls.Config) {
 	}
 }
 
+// requestClientCert configures the server to request client certificates.
+// Certificates are not required nor verified during the handshake,
+// they are verified by the authenticator.
+func requestClientCert(config *tls.Config) {
+	config.ClientAuth = tls.RequestClientCert
+}
+
 func run(l *slog.Logger) error {
 	log.SetLogger(logr.FromSlogHandler(l.Handler()))
 
@@ -54,11 +67,19 @@ func run(l *slog.Logger) error {
 	var tlsCertificateKeyPath string
 	var enableMetrics bool
 	var metricsAddress string
+	var enableAggregatedAPI bool
+	var enableImpersonation bool
+	var enableTracing bool
+	var enableAdminAPI bool
 	flag.BoolVar(&enableTLS, "enable-tls", true, "Toggle TLS enablement.")
 	flag.StringVar(&tlsCertificatePath, "cert-path", "", "Path to TLS certificate store.")
 	flag.StringVar(&tlsCertificateKeyPath, "key-path", "", "Path to TLS private key.")
 	flag.BoolVar(&enableMetrics, "enable-metrics", true, "Enable metrics server.")
 	flag.StringVar(&metricsAddress, "metrics-address", ":9100", "metrics server address.")
+	flag.BoolVar(&enableAggregatedAPI, "enable-aggregated-api", false, "Serve namespaces as a Kubernetes aggregated API.")
+	flag.BoolVar(&enableImpersonation, "enable-impersonation", false, "Serve requests as the users impersonated via the Impersonate-* headers.")
+	flag.BoolVar(&enableTracing, "enable-tracing", false, "Export traces via OTLP, configured with the standard OTEL_* environment variables.")
+	flag.BoolVar(&enableAdminAPI, "enable-admin-api", false, "Serve the admin endpoints explaining the access of subjects to namespaces.")
 	flag.Parse()
 
 	reg := metrics.Registry
@@ -67,21 +88,83 @@ func run(l *slog.Logger) error {
 	// get config
 	cfg := ctrl.GetConfigOrDie()
 
+	// setup context
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	ctx = nslog.SetLoggerIntoContext(ctx, l)
+
+	// setup tracing
+	if enableTracing {
+		shutdown, err := tracing.Setup(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			//nolint:contextcheck
+			if err := shutdown(sctx); err != nil {
+				l.Error("error flushing traces", "error", err)
+			}
+		}()
+	}
+
 	// build the request authenticator
-	ar, err := NewAuthenticator(AuthenticatorOptions{
+	oidcOpts, err := GetOIDCOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	authnCfg, err := GetAuthenticationConfigurationFromEnv()
+	if err != nil {
+		return err
+	}
+	authOpts := AuthenticatorOptions{
 		Config:         cfg,
 		UsernameHeader: GetUsernameHeaderFromEnv(),
 		GroupsHeader:   GetGroupsHeaderFromEnv(),
-	})
+		RequestHeader:  GetRequestHeaderOptionsFromEnv(),
+		ClientCert:     GetClientCertOptionsFromEnv(),
+		OIDC:           oidcOpts,
+		Configuration:  authnCfg,
+		Registerer:     reg,
+	}
+	if authnCfg, err = authOpts.AuthenticationConfiguration(); err != nil {
+		return err
+	}
+	verifyClientCert := authnCfg.RequiresClientCertificates()
+	switch {
+	case verifyClientCert && !enableTLS:
+		return errors.New("request header and client certificate authentication require TLS to be enabled")
+	case enableImpersonation && usesImpersonationHeaders(authnCfg):
+		return errors.New("impersonation can not be enabled when authenticating users from the Impersonate-* headers")
+	case enableImpersonation && enableAggregatedAPI:
+		return errors.New("impersonation is performed by the Kubernetes APIServer in aggregated API mode")
+	case enableAdminAPI && enableAggregatedAPI:
+		return errors.New("admin endpoints are not served in aggregated API mode")
+	case authnCfg.Has(AuthenticatorTypeHeader):
+		l.Warn("header authentication trusts user information from any caller, prefer request header authentication")
+	}
+	ar, err := NewAuthenticator(ctx, authOpts)
 	if err != nil {
 		return err
 	}
 
-	// setup context
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
-
-	ctx = nslog.SetLoggerIntoContext(ctx, l)
+	// build the auditor, if configured
+	auditOpts, err := audit.GetOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	var auditor *audit.Auditor
+	if auditOpts != nil {
+		if enableAggregatedAPI {
+			return errors.New("requests are audited by the Kubernetes APIServer in aggregated API mode")
+		}
+		if auditor, err = audit.NewFromOptions(ctx, *auditOpts); err != nil {
+			return err
+		}
+	}
 
 	// create resource cache
 	l.Info("creating resource cache")
@@ -89,20 +172,26 @@ func run(l *slog.Logger) error {
 	if err != nil {
 		return err
 	}
-	resourceCache, err := resourcecache.BuildAndStart(ctx, cacheCfg)
+	resourceCache, err := resourcecache.Build(cacheCfg)
 	if err != nil {
 		return err
 	}
 
-	// create access cache
-	l.Info("creating access cache")
-	accessCache, err := buildAndStartSynchronizedAccessCache(ctx, resourceCache, reg)
+	// create an access cache per access profile
+	l.Info("creating access caches")
+	accessCaches, err := buildSynchronizedAccessCaches(ctx, resourceCache, reg)
 	if err != nil {
 		return err
 	}
 
+	// restore access caches from the last snapshots, if any
+	warm, err := accessCaches.LoadSnapshot()
+	if err != nil {
+		l.Warn("unable to restore access caches from snapshots", "error", err)
+	}
+
 	// create the namespace lister
-	nsl := NewSubjectNamespaceLister(accessCache)
+	nsl := NewProfileNamespaceLister(resourcecache.DefaultAccessProfileName, accessCaches.NamespaceListers())
 
 	// build and start http metrics server
 	if enableMetrics {
@@ -139,12 +228,85 @@ func run(l *slog.Logger) error {
 		l.Info("metrics server disabled via flags")
 	}
 
-	// build http api server
-	l.Info("building api server")
-	s := NewAPIServer(l, ar, nsl, reg).
-		WithTLS(enableTLS).
-		WithTLSOpts(loadTLSCert(l, tlsCertificatePath, tlsCertificateKeyPath))
+	// build the api server
+	var resourceCacheSynced atomic.Bool
+	readinessChecks := NewReadinessChecks(
+		resourceCacheSynced.Load,
+		accessCaches,
+		resourcecache.GetValidStalenessThresholdFromEnvOrZero(ctx),
+		resourcecache.GetSnapshotReadyFromEnv(ctx),
+	)
+	var start func(context.Context) error
+	if enableAggregatedAPI {
+		l.Info("building aggregated api server")
+		as, err := NewAggregatedAPIServer(AggregatedAPIServerOptions{
+			CertPath:        tlsCertificatePath,
+			KeyPath:         tlsCertificateKeyPath,
+			Lister:          nsl,
+			ReadinessChecks: readinessChecks,
+		})
+		if err != nil {
+			return err
+		}
+		start = func(ctx context.Context) error { return as.PrepareRun().RunWithContext(ctx) }
+	} else {
+		l.Info("building api server")
+		tlsOpts := []func(*tls.Config){loadTLSCert(l, tlsCertificatePath, tlsCertificateKeyPath)}
+		if verifyClientCert {
+			tlsOpts = append(tlsOpts, requestClientCert)
+		}
+		s := NewAPIServer(l, ar, nsl, reg, readinessChecks...).
+			WithTLS(enableTLS).
+			WithTLSOpts(tlsOpts...).
+			WithAuditor(auditor)
+		if enableImpersonation || enableAdminAPI {
+			az, err := NewSubjectAccessReviewAuthorizer(cfg)
+			if err != nil {
+				return err
+			}
+			if enableImpersonation {
+				s.WithImpersonation(az)
+			}
+			if enableAdminAPI {
+				pp, err := resourcecache.GetAccessProfilesFromEnv()
+				if err != nil {
+					return err
+				}
+				// roles not granting any access are not cached, so they are read live
+				live, err := client.New(cfg, client.Options{})
+				if err != nil {
+					return err
+				}
+				s.WithAccessExplainer(NewAccessExplainer(resourceCache, live, pp...), az)
+			}
+		}
+		start = s.Start
+	}
+
+	// start the server.
+	// If the access caches have been restored from snapshots, requests are served
+	// with stale data while the caches are synchronizing.
+	serverErr := make(chan error, 1)
+	serve := func() { go func() { serverErr <- start(ctx) }() }
+	if warm {
+		l.Info("starting api server with access caches restored from snapshots")
+		serve()
+	}
 
-	// start the server
-	return s.Start(ctx)
+	// start caches
+	l.Info("starting resource cache")
+	if err := resourcecache.Start(ctx, resourceCache); err != nil {
+		return err
+	}
+	resourceCacheSynced.Store(true)
+	l.Info("starting access caches")
+	if err := startSynchronizedAccessCaches(ctx, accessCaches); err != nil {
+		return err
+	}
+
+	if !warm {
+		l.Info("starting api server")
+		serve()
+	}
+	return <-serverErr
 }
@@ -181,6 +181,26 @@ func (m *MockFakeSubjectNamespacesLister) EXPECT() *MockFakeSubjectNamespacesLis
 	return m.recorder
 }
 
+// GetWithGeneration mocks base method.
+func (m *MockFakeSubjectNamespacesLister) GetWithGeneration(name string, subjects ...v10.Subject) (*v1.Namespace, uint64) {
+	m.ctrl.T.Helper()
+	varargs := []any{name}
+	for _, a := range subjects {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetWithGeneration", varargs...)
+	ret0, _ := ret[0].(*v1.Namespace)
+	ret1, _ := ret[1].(uint64)
+	return ret0, ret1
+}
+
+// GetWithGeneration indicates an expected call of GetWithGeneration.
+func (mr *MockFakeSubjectNamespacesListerMockRecorder) GetWithGeneration(name any, subjects ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{name}, subjects...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithGeneration", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).GetWithGeneration), varargs...)
+}
+
 // List mocks base method.
 func (m *MockFakeSubjectNamespacesLister) List(subjects ...v10.Subject) []v1.Namespace {
 	m.ctrl.T.Helper()
@@ -198,3 +218,64 @@ func (mr *MockFakeSubjectNamespacesListerMockRecorder) List(subjects ...any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).List), subjects...)
 }
+
+// ListSubjects mocks base method.
+func (m *MockFakeSubjectNamespacesLister) ListSubjects(namespace string) []v10.Subject {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjects", namespace)
+	ret0, _ := ret[0].([]v10.Subject)
+	return ret0
+}
+
+// ListSubjects indicates an expected call of ListSubjects.
+func (mr *MockFakeSubjectNamespacesListerMockRecorder) ListSubjects(namespace any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjects", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).ListSubjects), namespace)
+}
+
+// ListWithGeneration mocks base method.
+func (m *MockFakeSubjectNamespacesLister) ListWithGeneration(subjects ...v10.Subject) ([]v1.Namespace, uint64) {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range subjects {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListWithGeneration", varargs...)
+	ret0, _ := ret[0].([]v1.Namespace)
+	ret1, _ := ret[1].(uint64)
+	return ret0, ret1
+}
+
+// ListWithGeneration indicates an expected call of ListWithGeneration.
+func (mr *MockFakeSubjectNamespacesListerMockRecorder) ListWithGeneration(subjects ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithGeneration", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).ListWithGeneration), subjects...)
+}
+
+// Restocked mocks base method.
+func (m *MockFakeSubjectNamespacesLister) Restocked() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restocked")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Restocked indicates an expected call of Restocked.
+func (mr *MockFakeSubjectNamespacesListerMockRecorder) Restocked() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restocked", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).Restocked))
+}
+
+// Stale mocks base method.
+func (m *MockFakeSubjectNamespacesLister) Stale() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stale")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Stale indicates an expected call of Stale.
+func (mr *MockFakeSubjectNamespacesListerMockRecorder) Stale() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stale", reflect.TypeOf((*MockFakeSubjectNamespacesLister)(nil).Stale))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const namespaceFieldName string = "metadata.name"
+
+// namespaceFilter selects namespaces by label and field selectors
+type namespaceFilter struct {
+	labelSelector labels.Selector
+	fieldSelector fields.Selector
+}
+
+// newNamespaceFilter builds a namespaceFilter.
+// It returns an error if the field selector uses fields not supported for Namespaces.
+func newNamespaceFilter(ls labels.Selector, fs fields.Selector) (*namespaceFilter, error) {
+	if ls == nil {
+		ls = labels.Everything()
+	}
+	if fs == nil {
+		fs = fields.Everything()
+	}
+
+	for _, r := range fs.Requirements() {
+		if r.Field != namespaceFieldName {
+			return nil, fmt.Errorf("field label not supported: %s", r.Field)
+		}
+	}
+
+	return &namespaceFilter{
+		labelSelector: ls,
+		fieldSelector: fs,
+	}, nil
+}
+
+// Empty returns true if the filter selects all the namespaces
+func (f *namespaceFilter) Empty() bool {
+	return f.labelSelector.Empty() && f.fieldSelector.Empty()
+}
+
+// Matches returns true if the namespace matches both the label and field selectors
+func (f *namespaceFilter) Matches(ns *corev1.Namespace) bool {
+	return f.labelSelector.Matches(labels.Set(ns.GetLabels())) &&
+		f.fieldSelector.Matches(fields.Set{namespaceFieldName: ns.GetName()})
+}
+
+// Filter returns the namespaces matching the filter
+func (f *namespaceFilter) Filter(nn []corev1.Namespace) []corev1.Namespace {
+	if f.Empty() {
+		return nn
+	}
+
+	fnn := []corev1.Namespace{}
+	for _, ns := range nn {
+		if f.Matches(&ns) {
+			fnn = append(fnn, ns)
+		}
+	}
+	return fnn
+}
+
+// FilterWatch filters the events of a namespace watch.
+// As the watch is not aware of the filter, events are translated to keep clients consistent:
+// a namespace starting to match the filter is ADDED, while a namespace not matching
+// the filter anymore is DELETED.
+func (f *namespaceFilter) FilterWatch(w watch.Interface) watch.Interface {
+	if f.Empty() {
+		return w
+	}
+
+	// names of the namespaces the client has been notified about
+	matched := map[string]struct{}{}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		ns, ok := in.Object.(*corev1.Namespace)
+		if !ok {
+			return in, true
+		}
+
+		_, wasMatching := matched[ns.GetName()]
+		isMatching := in.Type != watch.Deleted && f.Matches(ns)
+
+		switch {
+		case isMatching && wasMatching:
+			return in, true
+		case isMatching:
+			matched[ns.GetName()] = struct{}{}
+			return watch.Event{Type: watch.Added, Object: ns}, true
+		case wasMatching:
+			delete(matched, ns.GetName())
+			return watch.Event{Type: watch.Deleted, Object: ns}, true
+		default:
+			return in, false
+		}
+	})
+}
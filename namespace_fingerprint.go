@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// namespacesFingerprint returns a digest of the provided namespaces.
+// It changes whenever a namespace is added or removed, or whenever the resourceVersion,
+// the labels, or the annotations of a namespace change, virtual ones included.
+//
+// Unlike the cache generation, the fingerprint only depends on the data, so it is
+// consistent across restarts and replicas.
+func namespacesFingerprint(nn []corev1.Namespace) string {
+	h := sha256.New()
+	for _, ns := range nn {
+		writeFingerprintFields(h, ns.GetName(), ns.GetResourceVersion())
+		writeFingerprintMap(h, ns.GetLabels())
+		writeFingerprintMap(h, ns.GetAnnotations())
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// writeFingerprintMap writes the entries of the map sorted by key
+func writeFingerprintMap(h hash.Hash, m map[string]string) {
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		writeFingerprintFields(h, k, m[k])
+	}
+	h.Write([]byte{1})
+}
+
+// writeFingerprintFields writes the values separated by a NUL byte
+func writeFingerprintFields(h hash.Hash, vv ...string) {
+	for _, v := range vv {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+}
@@ -4,9 +4,35 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // NamespaceLister represents a lister that returns the list of namespaces a user has direct access to
 type NamespaceLister interface {
 	ListNamespaces(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error)
+	WatchNamespaces(ctx context.Context, username string, groups []string) (watch.Interface, error)
+	// GetNamespace retrieves the namespace, if the user can access it,
+	// along with the generation of the data it has been retrieved from
+	GetNamespace(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error)
+	// GetNamespaceSubjects retrieves the subjects having access to the namespace, if the user can access it
+	GetNamespaceSubjects(ctx context.Context, username string, groups []string, name string) (*NamespaceSubjects, error)
+	// Stale returns true if namespaces are retrieved from data that might be outdated
+	Stale(ctx context.Context) bool
+}
+
+// NamespaceSubjects lists the subjects having access to a namespace
+type NamespaceSubjects struct {
+	Namespace       string             `json:"namespace"`
+	Users           []NamespaceSubject `json:"users"`
+	Groups          []NamespaceSubject `json:"groups"`
+	ServiceAccounts []NamespaceSubject `json:"serviceAccounts"`
+}
+
+// NamespaceSubject is a subject having access to a namespace
+type NamespaceSubject struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Access is the kind of access the subject grants,
+	// as set in the `virtual.konflux-ci.dev/access` label of the namespaces
+	Access string `json:"access"`
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const continueTokenAPIVersion string = "meta.k8s.io/v1"
+
+var errInvalidContinueToken error = errors.New("continue key is not valid")
+
+// continueToken is the state of a paginated list.
+// It mirrors the format of the continue tokens issued by the Kubernetes APIServer.
+type continueToken struct {
+	APIVersion      string `json:"v"`
+	ResourceVersion int64  `json:"rv"`
+	StartKey        string `json:"start"`
+	// Fingerprint binds the token to the paginated data,
+	// as the resourceVersion is only meaningful to the process that issued it.
+	Fingerprint string `json:"fp"`
+}
+
+// encodeContinue returns the opaque continue token for a list
+// starting from the namespace named `start`.
+func encodeContinue(start string, resourceVersion int64, fingerprint string) (string, error) {
+	b, err := json.Marshal(&continueToken{
+		APIVersion:      continueTokenAPIVersion,
+		ResourceVersion: resourceVersion,
+		StartKey:        start,
+		Fingerprint:     fingerprint,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeContinue parses an opaque continue token
+func decodeContinue(value string) (*continueToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidContinueToken, err)
+	}
+
+	t := continueToken{}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidContinueToken, err)
+	}
+
+	switch {
+	case t.APIVersion != continueTokenAPIVersion:
+		return nil, fmt.Errorf("%w: unrecognized encoded version %s", errInvalidContinueToken, t.APIVersion)
+	case t.ResourceVersion <= 0:
+		return nil, fmt.Errorf("%w: incorrect encoded resourceVersion", errInvalidContinueToken)
+	case t.StartKey == "":
+		return nil, fmt.Errorf("%w: encoded start key empty", errInvalidContinueToken)
+	case t.Fingerprint == "":
+		return nil, fmt.Errorf("%w: encoded fingerprint empty", errInvalidContinueToken)
+	default:
+		return &t, nil
+	}
+}
+
+// paginate reduces the namespaces in the list to the page identified by limit and continue.
+// Items are expected to be sorted by name.
+// If more namespaces are available, the list's Continue and RemainingItemCount are set.
+//
+// A BadRequest StatusError is returned if the continue token is not valid,
+// while an Expired StatusError is returned if the continue token refers to
+// cache data that have been replaced in the meantime, or that have been served
+// by another replica or by a previous process.
+func paginate(nn *corev1.NamespaceList, limit int64, cont string) error {
+	// the fingerprint of the full list, so it does not change across pages
+	fp := ""
+	if cont != "" || (limit > 0 && int64(len(nn.Items)) > limit) {
+		fp = namespacesFingerprint(nn.Items)
+	}
+
+	if cont != "" {
+		t, err := decodeContinue(cont)
+		if err != nil {
+			return kerrors.NewBadRequest(err.Error())
+		}
+
+		if strconv.FormatInt(t.ResourceVersion, 10) != nn.ResourceVersion || t.Fingerprint != fp {
+			return kerrors.NewResourceExpired("The provided continue parameter is too old to display a consistent list result. You can start a new list without the continue parameter.")
+		}
+
+		// skip namespaces already returned
+		i := sort.Search(len(nn.Items), func(i int) bool { return nn.Items[i].GetName() >= t.StartKey })
+		nn.Items = nn.Items[i:]
+	}
+
+	if limit <= 0 || int64(len(nn.Items)) <= limit {
+		return nil
+	}
+
+	rv, err := strconv.ParseInt(nn.ResourceVersion, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	c, err := encodeContinue(nn.Items[limit].GetName(), rv, fp)
+	if err != nil {
+		return err
+	}
+
+	remaining := int64(len(nn.Items)) - limit
+	nn.Items = nn.Items[:limit]
+	nn.Continue = c
+	nn.RemainingItemCount = &remaining
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversionscheme "k8s.io/apimachinery/pkg/apis/meta/internalversion/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var (
+	tableGroupVersionKind                 = metav1.SchemeGroupVersion.WithKind("Table")
+	partialObjectMetadataGroupVersionKind = metav1.SchemeGroupVersion.WithKind("PartialObjectMetadata")
+)
+
+var namespaceTableColumnDefinitions = []metav1.TableColumnDefinition{
+	{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+	{Name: "Status", Type: "string", Description: "The status of the namespace"},
+	{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+	{Name: "Access", Type: "string", Description: "The kind of the subject the namespace is accessible by"},
+	{Name: "Visibility", Type: "string", Description: "Whether the namespace is accessible by all the authenticated users or not"},
+}
+
+// isTableRequested returns true if the client requested the response to be converted to a Table
+func isTableRequested(target *schema.GroupVersionKind) bool {
+	return target != nil && *target == tableGroupVersionKind
+}
+
+// newNamespaceTable builds a Table out of the provided namespaces.
+// Column definitions are omitted if TableOptions's NoHeaders is set.
+// Each row embeds the namespace as requested by TableOptions's IncludeObject.
+func newNamespaceTable(nn []corev1.Namespace, opts *metav1.TableOptions) (*metav1.Table, error) {
+	t := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       tableGroupVersionKind.Kind,
+			APIVersion: tableGroupVersionKind.GroupVersion().String(),
+		},
+		Rows: make([]metav1.TableRow, 0, len(nn)),
+	}
+	if !opts.NoHeaders {
+		t.ColumnDefinitions = namespaceTableColumnDefinitions
+	}
+
+	for _, ns := range nn {
+		r := metav1.TableRow{
+			Cells: namespaceTableRowCells(&ns.ObjectMeta, ns.Status.Phase),
+		}
+
+		o, err := namespaceTableRowObject(ns, opts.IncludeObject)
+		if err != nil {
+			return nil, err
+		}
+		r.Object = o
+
+		t.Rows = append(t.Rows, r)
+	}
+	return t, nil
+}
+
+// namespaceTableRowCells returns the cells of the row describing a namespace
+func namespaceTableRowCells(om *metav1.ObjectMeta, phase corev1.NamespacePhase) []any {
+	return []any{
+		om.GetName(),
+		string(phase),
+		translateTimestampSince(om.GetCreationTimestamp()),
+		om.GetLabels()[cache.VirtualLabelKeyAccess],
+		om.GetLabels()[cache.VirtualLabelKeyVisibility],
+	}
+}
+
+// namespaceTableRowObject encodes the namespace as requested by the IncludeObjectPolicy
+func namespaceTableRowObject(ns corev1.Namespace, policy metav1.IncludeObjectPolicy) (runtime.RawExtension, error) {
+	var o any
+	switch policy {
+	case metav1.IncludeNone:
+		return runtime.RawExtension{}, nil
+	case metav1.IncludeObject:
+		ns.TypeMeta = metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: corev1.SchemeGroupVersion.Version,
+		}
+		o = ns
+	case metav1.IncludeMetadata, "":
+		o = metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       partialObjectMetadataGroupVersionKind.Kind,
+				APIVersion: partialObjectMetadataGroupVersionKind.GroupVersion().String(),
+			},
+			ObjectMeta: ns.ObjectMeta,
+		}
+	default:
+		return runtime.RawExtension{}, fmt.Errorf("unsupported includeObject value: %s", policy)
+	}
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: b}, nil
+}
+
+// translateTimestampSince returns the elapsed time since timestamp in
+// human-readable approximation, as kubectl does
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
+
+// decodeTableOptions parses the TableOptions from the request's query parameters.
+// A BadRequest StatusError is returned if the options are not valid.
+func decodeTableOptions(r *http.Request) (*metav1.TableOptions, error) {
+	opts := metav1.TableOptions{}
+	if err := metainternalversionscheme.ParameterCodec.DecodeParameters(r.URL.Query(), metav1.SchemeGroupVersion, &opts); err != nil {
+		return nil, kerrors.NewBadRequest(err.Error())
+	}
+
+	switch opts.IncludeObject {
+	case "", metav1.IncludeNone, metav1.IncludeMetadata, metav1.IncludeObject:
+		return &opts, nil
+	default:
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("unsupported includeObject value: %s", opts.IncludeObject))
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/konflux-ci/namespace-lister/internal/contextkey"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const queryParamProfile string = "profile"
+
+var _ NamespaceLister = &profileNamespaceLister{}
+
+type profileNamespaceLister struct {
+	defaultProfile string
+	listers        map[string]NamespaceLister
+}
+
+// NewProfileNamespaceLister builds a NamespaceLister that serves requests with the lister
+// of the access profile selected in the context.
+// Requests not selecting any profile are served by the lister of the default profile.
+func NewProfileNamespaceLister(defaultProfile string, listers map[string]NamespaceLister) NamespaceLister {
+	return &profileNamespaceLister{
+		defaultProfile: defaultProfile,
+		listers:        listers,
+	}
+}
+
+// ListNamespaces retrieves the namespaces the provided user can access with the selected access profile
+func (p *profileNamespaceLister) ListNamespaces(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+	l, err := p.lister(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.ListNamespaces(ctx, username, groups)
+}
+
+// WatchNamespaces watches the namespaces the provided user can access with the selected access profile
+func (p *profileNamespaceLister) WatchNamespaces(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+	l, err := p.lister(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.WatchNamespaces(ctx, username, groups)
+}
+
+// GetNamespace retrieves the namespace with the provided name if the user can access it with the selected access profile
+func (p *profileNamespaceLister) GetNamespace(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+	l, err := p.lister(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return l.GetNamespace(ctx, username, groups, name)
+}
+
+// GetNamespaceSubjects retrieves the subjects having access to the namespace with the selected access profile,
+// if the user can access it
+func (p *profileNamespaceLister) GetNamespaceSubjects(ctx context.Context, username string, groups []string, name string) (*NamespaceSubjects, error) {
+	l, err := p.lister(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return l.GetNamespaceSubjects(ctx, username, groups, name)
+}
+
+// Stale returns true if the lister of the selected access profile is serving data that might be outdated
+func (p *profileNamespaceLister) Stale(ctx context.Context) bool {
+	l, err := p.lister(ctx)
+	return err == nil && l.Stale(ctx)
+}
+
+// lister returns the lister of the access profile selected in the context.
+// A BadRequest error is returned if the profile is not known.
+func (p *profileNamespaceLister) lister(ctx context.Context) (NamespaceLister, error) {
+	n := p.defaultProfile
+	if v, ok := ctx.Value(contextkey.ContextKeyAccessProfile).(string); ok && v != "" {
+		n = v
+	}
+
+	l, ok := p.listers[n]
+	if !ok {
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("unknown access profile %q", n))
+	}
+	return l, nil
+}
+
+// addAccessProfileMiddleware stores in the request's context the access profile
+// selected via the profile query parameter
+func addAccessProfileMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if p := r.URL.Query().Get(queryParamProfile); p != "" {
+			ctx = context.WithValue(ctx, contextkey.ContextKeyAccessProfile, p)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
@@ -0,0 +1,96 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+var _ = Describe("ProfileNamespaceLister", func() {
+	var handler http.Handler
+
+	listerReturning := func(name string, stale bool) namespacelister.NamespaceLister {
+		nn := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+		return NamespaceListerMock{
+			ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				return &corev1.NamespaceList{Items: nn}, nil
+			},
+			GetNamespaceFunc: func(ctx context.Context, username string, groups []string, n string) (*corev1.Namespace, string, error) {
+				return nn[0].DeepCopy(), "1", nil
+			},
+			StaleFunc: func() bool { return stale },
+		}
+	}
+
+	BeforeEach(func() {
+		ar := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "myuser"}}, true, nil
+		})
+		nsl := namespacelister.NewProfileNamespaceLister("viewer", map[string]namespacelister.NamespaceLister{
+			"viewer":      listerReturning("viewer-ns", false),
+			"contributor": listerReturning("contributor-ns", true),
+		})
+		handler = namespacelister.NewAPIServer(slog.Default(), ar, nsl, nil).Handler
+	})
+
+	get := func(ctx context.Context, path string) *http.Response {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	DescribeTable("lists namespaces with the selected profile", func(ctx context.Context, path, expectedNamespace, expectedWarning string) {
+		// when
+		rsp := get(ctx, path)
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		nn := corev1.NamespaceList{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&nn)).To(Succeed())
+		Expect(nn.Items).To(ConsistOf(HaveField("Name", expectedNamespace)))
+		Expect(rsp.Header.Get(constants.HttpWarning)).To(Equal(expectedWarning))
+	},
+		Entry("default profile", "/api/v1/namespaces", "viewer-ns", ""),
+		Entry("explicit default profile", "/api/v1/namespaces?profile=viewer", "viewer-ns", ""),
+		Entry("other profile", "/api/v1/namespaces?profile=contributor", "contributor-ns", constants.HttpWarningStaleData),
+		Entry("other profile with list options", "/api/v1/namespaces?profile=contributor&limit=10", "contributor-ns", constants.HttpWarningStaleData),
+	)
+
+	It("gets a namespace with the selected profile", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, "/api/v1/namespaces/contributor-ns?profile=contributor")
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+		ns := corev1.Namespace{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&ns)).To(Succeed())
+		Expect(ns.Name).To(Equal("contributor-ns"))
+	})
+
+	It("replies BadRequest if the profile is not known", func(ctx context.Context) {
+		// when
+		rsp := get(ctx, "/api/v1/namespaces?profile=unknown")
+
+		// then
+		Expect(rsp.StatusCode).To(Equal(http.StatusBadRequest))
+		s := metav1.Status{}
+		Expect(json.NewDecoder(rsp.Body).Decode(&s)).To(Succeed())
+		Expect(s.Reason).To(Equal(metav1.StatusReasonBadRequest))
+		Expect(s.Message).To(ContainSubstring(`unknown access profile "unknown"`))
+	})
+})
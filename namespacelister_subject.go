@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,6 +17,11 @@ var _ NamespaceLister = &subjectNamespaceLister{}
 
 type SubjectNamespacesLister interface {
 	List(subjects ...rbacv1.Subject) []corev1.Namespace
+	ListWithGeneration(subjects ...rbacv1.Subject) ([]corev1.Namespace, uint64)
+	GetWithGeneration(name string, subjects ...rbacv1.Subject) (*corev1.Namespace, uint64)
+	ListSubjects(namespace string) []rbacv1.Subject
+	Restocked() <-chan struct{}
+	Stale() bool
 }
 
 type subjectNamespaceLister struct {
@@ -26,10 +35,18 @@ func NewSubjectNamespaceLister(subjectNamespacesLister SubjectNamespacesLister)
 	}
 }
 
-// ListNamespaces retrieves the namespaces the provided user can access from a cache calculated ahead of time
+// ListNamespaces retrieves the namespaces the provided user can access from a cache calculated ahead of time.
+// Namespaces are sorted by name and the list's ResourceVersion is set to the generation of the cache data.
 func (c *subjectNamespaceLister) ListNamespaces(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
 	subs := c.subjects(username, groups)
-	nn := c.subjectNamespacesLister.List(subs...)
+	nn, g := c.subjectNamespacesLister.ListWithGeneration(subs...)
+
+	// sort namespaces by name, so results are stable for pagination.
+	// cached data is shared, so sort a copy.
+	nn = slices.Clone(nn)
+	slices.SortFunc(nn, func(a, b corev1.Namespace) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
 
 	// list all namespaces
 	return &corev1.NamespaceList{
@@ -40,10 +57,65 @@ func (c *subjectNamespaceLister) ListNamespaces(ctx context.Context, username st
 			Kind:       "NamespaceList",
 			APIVersion: corev1.SchemeGroupVersion.Version,
 		},
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: strconv.FormatUint(g, 10),
+		},
 		Items: nn,
 	}, nil
 }
 
+// GetNamespace retrieves the namespace with the provided name if the user can access it.
+// A NotFound error is returned if the namespace does not exist as well as if the user can not access it,
+// so it is not disclosed whether the namespace exists.
+// The generation of the cache data the namespace has been retrieved from is returned too.
+func (c *subjectNamespaceLister) GetNamespace(ctx context.Context, username string, groups []string, name string) (*corev1.Namespace, string, error) {
+	subs := c.subjects(username, groups)
+	cns, g := c.subjectNamespacesLister.GetWithGeneration(name, subs...)
+	if cns == nil {
+		return nil, "", kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+	}
+
+	ns := cns.DeepCopy()
+	ns.TypeMeta = metav1.TypeMeta{
+		Kind:       "Namespace",
+		APIVersion: corev1.SchemeGroupVersion.Version,
+	}
+	return ns, strconv.FormatUint(g, 10), nil
+}
+
+// GetNamespaceSubjects retrieves the users, groups, and service accounts having access to the namespace
+// with the provided name if the user can access it.
+// As GetNamespace does, a NotFound error is returned if the user can not access the namespace.
+func (c *subjectNamespaceLister) GetNamespaceSubjects(ctx context.Context, username string, groups []string, name string) (*NamespaceSubjects, error) {
+	if _, _, err := c.GetNamespace(ctx, username, groups, name); err != nil {
+		return nil, err
+	}
+
+	ns := &NamespaceSubjects{
+		Namespace:       name,
+		Users:           []NamespaceSubject{},
+		Groups:          []NamespaceSubject{},
+		ServiceAccounts: []NamespaceSubject{},
+	}
+	for _, sub := range c.subjectNamespacesLister.ListSubjects(name) {
+		s := NamespaceSubject{Name: sub.Name, Namespace: sub.Namespace, Access: cache.VirtualAccessKind(sub)}
+		switch sub.Kind {
+		case rbacv1.UserKind:
+			ns.Users = append(ns.Users, s)
+		case rbacv1.GroupKind:
+			ns.Groups = append(ns.Groups, s)
+		case rbacv1.ServiceAccountKind:
+			ns.ServiceAccounts = append(ns.ServiceAccounts, s)
+		}
+	}
+	return ns, nil
+}
+
+// Stale returns true if the cache is serving data restored from a snapshot
+func (c *subjectNamespaceLister) Stale(_ context.Context) bool {
+	return c.subjectNamespacesLister.Stale()
+}
+
 func (c *subjectNamespaceLister) subjects(username string, groups []string) []rbacv1.Subject {
 	subs := make([]rbacv1.Subject, len(groups)+1)
 
@@ -8,7 +8,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 
 	. "github.com/onsi/gomega"
 
@@ -38,14 +40,14 @@ var _ = Describe("Subjectnamespaceslister", func() {
 	It("parses service account", func(ctx context.Context) {
 		// set expectation
 		subjectNamespacesLister.EXPECT().
-			List(
+			ListWithGeneration(
 				rbacv1.Subject{
 					Kind:      "ServiceAccount",
 					Name:      "myserviceaccount",
 					Namespace: "mynamespace",
 				},
 			).
-			Return(enn).
+			Return(enn, uint64(1)).
 			Times(1)
 
 		// given
@@ -59,6 +61,9 @@ var _ = Describe("Subjectnamespaceslister", func() {
 					Kind:       "NamespaceList",
 					APIVersion: "v1",
 				},
+				ListMeta: metav1.ListMeta{
+					ResourceVersion: "1",
+				},
 				Items: enn,
 			}))
 	})
@@ -66,14 +71,14 @@ var _ = Describe("Subjectnamespaceslister", func() {
 	It("parses user", func(ctx context.Context) {
 		// set expectation
 		subjectNamespacesLister.EXPECT().
-			List(
+			ListWithGeneration(
 				rbacv1.Subject{
 					APIGroup: rbacv1.GroupName,
 					Kind:     "User",
 					Name:     "myuser",
 				},
 			).
-			Return(enn).
+			Return(enn, uint64(1)).
 			Times(1)
 
 		// given
@@ -87,7 +92,158 @@ var _ = Describe("Subjectnamespaceslister", func() {
 					Kind:       "NamespaceList",
 					APIVersion: "v1",
 				},
+				ListMeta: metav1.ListMeta{
+					ResourceVersion: "1",
+				},
 				Items: enn,
 			}))
 	})
+
+	It("sorts namespaces by name", func(ctx context.Context) {
+		// given
+		nn := []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "myns-b"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "myns-c"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "myns-a"}},
+		}
+		subjectNamespacesLister.EXPECT().
+			ListWithGeneration(gomock.Any()).
+			Return(nn, uint64(2)).
+			Times(1)
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		l, err := nl.ListNamespaces(ctx, "myuser", nil)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(l.ResourceVersion).To(Equal("2"))
+		Expect(l.Items).To(HaveLen(3))
+		Expect([]string{l.Items[0].Name, l.Items[1].Name, l.Items[2].Name}).
+			To(Equal([]string{"myns-a", "myns-b", "myns-c"}))
+		// cached data is not modified
+		Expect(nn[0].Name).To(Equal("myns-b"))
+	})
+
+	It("gets an accessible namespace", func(ctx context.Context) {
+		// given
+		subjectNamespacesLister.EXPECT().
+			GetWithGeneration(
+				"myns",
+				rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: "User", Name: "myuser"},
+				rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: "Group", Name: "mygroup"},
+			).
+			Return(&enn[0], uint64(4)).
+			Times(1)
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		ns, g, err := nl.GetNamespace(ctx, "myuser", []string{"mygroup"}, "myns")
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(g).To(Equal("4"))
+		Expect(ns.Kind).To(Equal("Namespace"))
+		Expect(ns.APIVersion).To(Equal("v1"))
+		Expect(ns.ObjectMeta).To(Equal(enn[0].ObjectMeta))
+	})
+
+	It("returns NotFound for a not accessible namespace", func(ctx context.Context) {
+		// given
+		subjectNamespacesLister.EXPECT().
+			GetWithGeneration("other", gomock.Any()).
+			Return(nil, uint64(1)).
+			Times(1)
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		_, _, err := nl.GetNamespace(ctx, "myuser", nil, "other")
+
+		// then
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("lists the subjects having access to an accessible namespace", func(ctx context.Context) {
+		// given
+		subjectNamespacesLister.EXPECT().
+			GetWithGeneration("myns", gomock.Any()).
+			Return(&enn[0], uint64(1)).
+			Times(1)
+		subjectNamespacesLister.EXPECT().
+			ListSubjects("myns").
+			Return([]rbacv1.Subject{
+				{APIGroup: rbacv1.GroupName, Kind: "Group", Name: "mygroup"},
+				{APIGroup: rbacv1.GroupName, Kind: "User", Name: "myuser"},
+				{Kind: "ServiceAccount", Name: "mysa", Namespace: "myns"},
+			}).
+			Times(1)
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		ss, err := nl.GetNamespaceSubjects(ctx, "myuser", nil, "myns")
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ss).To(Equal(&namespacelister.NamespaceSubjects{
+			Namespace:       "myns",
+			Users:           []namespacelister.NamespaceSubject{{Name: "myuser", Access: "user"}},
+			Groups:          []namespacelister.NamespaceSubject{{Name: "mygroup", Access: "group"}},
+			ServiceAccounts: []namespacelister.NamespaceSubject{{Name: "mysa", Namespace: "myns", Access: "serviceaccount"}},
+		}))
+	})
+
+	It("does not list the subjects of a not accessible namespace", func(ctx context.Context) {
+		// given
+		subjectNamespacesLister.EXPECT().
+			GetWithGeneration("other", gomock.Any()).
+			Return(nil, uint64(1)).
+			Times(1)
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		_, err := nl.GetNamespaceSubjects(ctx, "myuser", nil, "other")
+
+		// then
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("streams namespace changes on restock", func(ctx context.Context) {
+		// given
+		userSubject := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: "User", Name: "myuser"}
+		modifiedNs := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "myns",
+			Labels: map[string]string{"key": "other-value"},
+		}}
+		addedNs := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "myns-2"}}
+
+		firstRestock, secondRestock := make(chan struct{}), make(chan struct{})
+		gomock.InOrder(
+			subjectNamespacesLister.EXPECT().Restocked().Return(firstRestock),
+			subjectNamespacesLister.EXPECT().List(userSubject).Return(enn),
+			subjectNamespacesLister.EXPECT().Restocked().Return(secondRestock),
+			subjectNamespacesLister.EXPECT().List(userSubject).Return([]corev1.Namespace{modifiedNs, addedNs}),
+			subjectNamespacesLister.EXPECT().Restocked().Return(make(chan struct{})),
+			subjectNamespacesLister.EXPECT().List(userSubject).Return([]corev1.Namespace{addedNs}),
+		)
+
+		nl := namespacelister.NewSubjectNamespaceLister(subjectNamespacesLister)
+
+		// when
+		w, err := nl.WatchNamespaces(ctx, "myuser", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		// then
+		eventName := func(e watch.Event) string {
+			return string(e.Type) + "/" + e.Object.(*corev1.Namespace).Name
+		}
+		Eventually(w.ResultChan()).Should(Receive(WithTransform(eventName, Equal("ADDED/myns"))))
+
+		close(firstRestock)
+		Eventually(w.ResultChan()).Should(Receive(WithTransform(eventName, Equal("MODIFIED/myns"))))
+		Eventually(w.ResultChan()).Should(Receive(WithTransform(eventName, Equal("ADDED/myns-2"))))
+
+		close(secondRestock)
+		Eventually(w.ResultChan()).Should(Receive(WithTransform(eventName, Equal("DELETED/myns"))))
+	})
 })
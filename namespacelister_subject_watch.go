@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchNamespaces streams the changes on the namespaces the provided user can access.
+// The first events are ADDED events for all the namespaces currently accessible by the user.
+// Then, each time the cache is restocked, the user's view is calculated again and compared
+// with the previous one to produce ADDED, MODIFIED, and DELETED events.
+func (c *subjectNamespaceLister) WatchNamespaces(ctx context.Context, username string, groups []string) (watch.Interface, error) {
+	subs := c.subjects(username, groups)
+
+	ch := make(chan watch.Event)
+	w := watch.NewProxyWatcher(ch)
+	go c.watch(ctx, subs, ch, w.StopChan())
+
+	return w, nil
+}
+
+func (c *subjectNamespaceLister) watch(ctx context.Context, subs []rbacv1.Subject, ch chan<- watch.Event, stop <-chan struct{}) {
+	defer close(ch)
+
+	known := map[string]corev1.Namespace{}
+	for {
+		// retrieve the restock notification channel before listing,
+		// so restocks happening while diffing are not lost
+		restocked := c.subjectNamespacesLister.Restocked()
+
+		nn := c.subjectNamespacesLister.List(subs...)
+		for _, e := range diffNamespaces(known, nn) {
+			select {
+			case ch <- e:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-restocked:
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// diffNamespaces calculates the watch events needed to move from the known namespaces
+// to the current ones. The known map is updated to reflect the current namespaces.
+// Events are sorted by namespace name.
+func diffNamespaces(known map[string]corev1.Namespace, current []corev1.Namespace) []watch.Event {
+	ee := []watch.Event{}
+
+	cm := make(map[string]struct{}, len(current))
+	for _, ns := range current {
+		cm[ns.GetName()] = struct{}{}
+
+		kns, ok := known[ns.GetName()]
+		switch {
+		case !ok:
+			ee = append(ee, namespaceEvent(watch.Added, ns))
+		case !equality.Semantic.DeepEqual(kns, ns):
+			ee = append(ee, namespaceEvent(watch.Modified, ns))
+		default:
+			continue
+		}
+		known[ns.GetName()] = ns
+	}
+
+	for n, ns := range known {
+		if _, ok := cm[n]; !ok {
+			ee = append(ee, namespaceEvent(watch.Deleted, ns))
+			delete(known, n)
+		}
+	}
+
+	slices.SortFunc(ee, func(a, b watch.Event) int {
+		return strings.Compare(
+			a.Object.(*corev1.Namespace).GetName(),
+			b.Object.(*corev1.Namespace).GetName())
+	})
+	return ee
+}
+
+func namespaceEvent(t watch.EventType, ns corev1.Namespace) watch.Event {
+	o := ns.DeepCopy()
+	o.TypeMeta = metav1.TypeMeta{
+		Kind:       "Namespace",
+		APIVersion: corev1.SchemeGroupVersion.Version,
+	}
+	return watch.Event{Type: t, Object: o}
+}
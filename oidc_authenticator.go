@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/coreos/go-oidc"
+	"github.com/konflux-ci/namespace-lister/internal/constants"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	"k8s.io/apiserver/pkg/apis/apiserver"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
+	tokenunion "k8s.io/apiserver/pkg/authentication/token/union"
+	oidcauthenticator "k8s.io/apiserver/plugin/pkg/authenticator/token/oidc"
+	"sigs.k8s.io/yaml"
+)
+
+// OIDCOptions allows to configure the local validation of JWTs issued by OIDC providers
+type OIDCOptions struct {
+	Issuers []OIDCIssuerOptions `json:"issuers"`
+}
+
+// OIDCIssuerOptions allows to configure the validation of JWTs issued by an OIDC provider
+type OIDCIssuerOptions struct {
+	// URL is the URL of the issuer. It must use the https scheme and match the iss claim of the tokens.
+	URL string `json:"url"`
+	// DiscoveryURL overrides the URL of the OpenID discovery document,
+	// that defaults to {URL}/.well-known/openid-configuration.
+	DiscoveryURL string `json:"discoveryURL,omitempty"`
+	// JWKSFile is the path of the JSON Web Key Set used to verify the tokens' signatures.
+	// If set, the discovery document is not retrieved.
+	JWKSFile string `json:"jwksFile,omitempty"`
+	// CertificateAuthorityFile is the path of the PEM encoded CA bundle used
+	// to connect to the issuer. If not set, the host's root CAs are used.
+	CertificateAuthorityFile string `json:"certificateAuthorityFile,omitempty"`
+	// Audiences are the accepted values of the aud claim of the tokens
+	Audiences []string `json:"audiences"`
+	// ClaimMappings configures how the user information is built from the tokens' claims
+	ClaimMappings OIDCClaimMappings `json:"claimMappings"`
+}
+
+// OIDCClaimMappings configures how the user information is built from the tokens' claims
+type OIDCClaimMappings struct {
+	Username OIDCClaimMapping `json:"username"`
+	Groups   OIDCClaimMapping `json:"groups,omitempty"`
+}
+
+// OIDCClaimMapping maps a claim to a user information, prepending the prefix to its values.
+// As for kube-apiserver, the prefix is required when the claim is set, so that OIDC identities
+// do not collide with the cluster ones. It can be set to an empty string to disable prefixing.
+type OIDCClaimMapping struct {
+	Claim  string  `json:"claim"`
+	Prefix *string `json:"prefix,omitempty"`
+}
+
+// NewOIDCAuthenticator builds an authenticator validating locally the bearer tokens issued
+// by the configured OIDC providers.
+// Tokens not issued by any of the configured issuers are ignored.
+// The provided context bounds the lifecycle of the background retrieval of the JWKS.
+func NewOIDCAuthenticator(ctx context.Context, opts OIDCOptions) (authenticator.Request, error) {
+	if len(opts.Issuers) == 0 {
+		return nil, errors.New("at least one issuer is required to build the OIDC authenticator")
+	}
+
+	tt := make([]authenticator.Token, 0, len(opts.Issuers))
+	for _, i := range opts.Issuers {
+		t, err := newOIDCTokenAuthenticator(ctx, i)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDC issuer %q: %w", i.URL, err)
+		}
+		tt = append(tt, t)
+	}
+	return bearertoken.New(tokenunion.New(tt...)), nil
+}
+
+func newOIDCTokenAuthenticator(ctx context.Context, opts OIDCIssuerOptions) (authenticator.Token, error) {
+	o := oidcauthenticator.Options{
+		JWTAuthenticator: apiserver.JWTAuthenticator{
+			Issuer: apiserver.Issuer{
+				URL:          opts.URL,
+				DiscoveryURL: opts.DiscoveryURL,
+				Audiences:    opts.Audiences,
+			},
+			ClaimMappings: apiserver.ClaimMappings{
+				Username: apiserver.PrefixedClaimOrExpression{
+					Claim:  opts.ClaimMappings.Username.Claim,
+					Prefix: opts.ClaimMappings.Username.Prefix,
+				},
+			},
+		},
+	}
+	if opts.ClaimMappings.Groups.Claim != "" {
+		o.JWTAuthenticator.ClaimMappings.Groups = apiserver.PrefixedClaimOrExpression{
+			Claim:  opts.ClaimMappings.Groups.Claim,
+			Prefix: opts.ClaimMappings.Groups.Prefix,
+		}
+	}
+
+	if opts.CertificateAuthorityFile != "" {
+		cp, err := newStaticCAContentFromFile("oidc-ca", opts.CertificateAuthorityFile)
+		if err != nil {
+			return nil, err
+		}
+		o.CAContentProvider = cp
+	}
+
+	if opts.JWKSFile != "" {
+		ks, err := readJWKSFile(opts.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		o.KeySet = ks
+	}
+
+	return oidcauthenticator.New(ctx, o)
+}
+
+var _ oidc.KeySet = &staticKeySet{}
+
+// staticKeySet verifies JWTs signatures with a fixed set of keys
+type staticKeySet struct {
+	keys []jose.JSONWebKey
+}
+
+// VerifySignature verifies the JWT signature with the first key matching its key ID.
+// If the JWT has no key ID, all the keys are tried.
+func (s *staticKeySet) VerifySignature(_ context.Context, jwt string) ([]byte, error) {
+	jws, err := jose.ParseSigned(jwt)
+	if err != nil {
+		return nil, fmt.Errorf("malformed jwt: %w", err)
+	}
+
+	kid := ""
+	if len(jws.Signatures) > 0 {
+		kid = jws.Signatures[0].Header.KeyID
+	}
+	for _, k := range s.keys {
+		if kid != "" && k.KeyID != kid {
+			continue
+		}
+		if p, err := jws.Verify(&k); err == nil {
+			return p, nil
+		}
+	}
+	return nil, errors.New("failed to verify jwt signature: no matching key found")
+}
+
+// readJWKSFile reads the JSON Web Key Set stored at the provided path
+func readJWKSFile(path string) (*staticKeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWKS file: %w", err)
+	}
+
+	ks := jose.JSONWebKeySet{}
+	if err := json.Unmarshal(b, &ks); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %q: %w", path, err)
+	}
+	if len(ks.Keys) == 0 {
+		return nil, fmt.Errorf("invalid JWKS file %q: no keys found", path)
+	}
+	return &staticKeySet{keys: ks.Keys}, nil
+}
+
+// GetOIDCOptionsFromEnv retrieves from environment variable the path of the file
+// configuring the OIDC issuers, and reads it.
+// If the environment variable is not set, OIDC authentication is disabled and nil is returned.
+func GetOIDCOptionsFromEnv() (*OIDCOptions, error) {
+	p := os.Getenv(constants.EnvOIDCConfigFile)
+	if p == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OIDC configuration file: %w", err)
+	}
+
+	opts := OIDCOptions{}
+	if err := yaml.UnmarshalStrict(b, &opts); err != nil {
+		return nil, fmt.Errorf("invalid OIDC configuration file %q: %w", p, err)
+	}
+	return &opts, nil
+}
@@ -0,0 +1,322 @@
+package main_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	jose "gopkg.in/go-jose/go-jose.v2"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/utils/ptr"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/mocks"
+)
+
+var _ = Describe("OIDCAuthenticator", func() {
+	const (
+		keyID    = "my-key"
+		audience = "namespace-lister"
+	)
+
+	var (
+		key    *rsa.PrivateKey
+		jwks   jose.JSONWebKeySet
+		issuer *httptest.Server
+		caFile string
+	)
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		jwks = jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: keyID, Algorithm: string(jose.RS256), Use: "sig"}}}
+
+		// in-process OIDC provider serving the discovery document and the JWKS
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   issuer.URL,
+				"jwks_uri": issuer.URL + "/keys",
+			})
+		})
+		mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(jwks)
+		})
+		issuer = httptest.NewTLSServer(mux)
+		DeferCleanup(issuer.Close)
+
+		caFile = filepath.Join(GinkgoT().TempDir(), "ca.crt")
+		Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: issuer.Certificate().Raw}), 0o600)).To(Succeed())
+	})
+
+	newToken := func(claims map[string]any) string {
+		GinkgoHelper()
+
+		s, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: keyID}},
+			(&jose.SignerOptions{}).WithType("JWT"))
+		Expect(err).NotTo(HaveOccurred())
+		p, err := json.Marshal(claims)
+		Expect(err).NotTo(HaveOccurred())
+		jws, err := s.Sign(p)
+		Expect(err).NotTo(HaveOccurred())
+		t, err := jws.CompactSerialize()
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	validClaims := func(iss string) map[string]any {
+		return map[string]any{
+			"iss":    iss,
+			"aud":    audience,
+			"sub":    "1234",
+			"email":  "my-user@example.com",
+			"groups": []string{"group1", "group2"},
+			"iat":    time.Now().Unix(),
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	issuerOptions := func(url string) namespacelister.OIDCIssuerOptions {
+		return namespacelister.OIDCIssuerOptions{
+			URL:       url,
+			Audiences: []string{audience},
+			ClaimMappings: namespacelister.OIDCClaimMappings{
+				Username: namespacelister.OIDCClaimMapping{Claim: "sub", Prefix: ptr.To("oidc:")},
+				Groups:   namespacelister.OIDCClaimMapping{Claim: "groups", Prefix: ptr.To("oidc:")},
+			},
+		}
+	}
+
+	writeJWKSFile := func() string {
+		GinkgoHelper()
+
+		p := filepath.Join(GinkgoT().TempDir(), "jwks.json")
+		b, err := json.Marshal(jwks)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(p, b, 0o600)).To(Succeed())
+		return p
+	}
+
+	authenticate := func(ctx context.Context, auth authenticator.Request, token string) (*authenticator.Response, bool, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		r.Header.Set("Authorization", "Bearer "+token)
+		return auth.AuthenticateRequest(r)
+	}
+
+	When("the JWKS is retrieved via discovery", func() {
+		var auth authenticator.Request
+
+		BeforeEach(func() {
+			o := issuerOptions(issuer.URL)
+			o.CertificateAuthorityFile = caFile
+
+			// the JWKS is retrieved in background until the context is invalidated
+			ctx, cancel := context.WithCancel(context.Background())
+			DeferCleanup(cancel)
+
+			var err error
+			auth, err = namespacelister.NewOIDCAuthenticator(ctx, namespacelister.OIDCOptions{
+				Issuers: []namespacelister.OIDCIssuerOptions{o},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("authenticates valid tokens mapping the claims", func(ctx context.Context) {
+			// given
+			token := newToken(validClaims(issuer.URL))
+
+			// when
+			var rs *authenticator.Response
+			Eventually(func(g Gomega) {
+				var ok bool
+				var err error
+				rs, ok, err = authenticate(ctx, auth, token)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+			}).Should(Succeed())
+
+			// then
+			Expect(rs.User.GetName()).To(Equal("oidc:1234"))
+			Expect(rs.User.GetGroups()).To(ConsistOf("oidc:group1", "oidc:group2"))
+		})
+
+		It("prefixes claims colliding with cluster identities", func(ctx context.Context) {
+			// given
+			c := validClaims(issuer.URL)
+			c["sub"] = "system:serviceaccount:kube-system:default"
+			c["groups"] = []string{"system:masters"}
+			token := newToken(c)
+
+			// when
+			var rs *authenticator.Response
+			Eventually(func(g Gomega) {
+				var ok bool
+				var err error
+				rs, ok, err = authenticate(ctx, auth, token)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(ok).To(BeTrue())
+			}).Should(Succeed())
+
+			// then
+			Expect(rs.User.GetName()).To(Equal("oidc:system:serviceaccount:kube-system:default"))
+			Expect(rs.User.GetGroups()).To(ConsistOf("oidc:system:masters"))
+		})
+
+		DescribeTable("rejects invalid tokens", func(ctx context.Context, mutate func(map[string]any)) {
+			// given
+			c := validClaims(issuer.URL)
+			mutate(c)
+			token := newToken(c)
+
+			// when
+			rs, ok, err := authenticate(ctx, auth, token)
+
+			// then
+			Expect(err).To(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(rs).To(BeNil())
+		},
+			Entry("with wrong audience", func(c map[string]any) { c["aud"] = "other" }),
+			Entry("expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }),
+		)
+
+		It("ignores tokens issued by other issuers", func(ctx context.Context) {
+			// given
+			token := newToken(validClaims("https://other.example.com"))
+
+			// when
+			rs, ok, err := authenticate(ctx, auth, token)
+
+			// then
+			Expect(err).To(MatchError(Not(ContainSubstring("oidc"))))
+			Expect(ok).To(BeFalse())
+			Expect(rs).To(BeNil())
+		})
+	})
+
+	When("the JWKS is read from file", func() {
+		const url = "https://issuer.example.com"
+
+		var auth authenticator.Request
+
+		BeforeEach(func(ctx context.Context) {
+			o := issuerOptions(url)
+			o.JWKSFile = writeJWKSFile()
+
+			var err error
+			auth, err = namespacelister.NewOIDCAuthenticator(ctx, namespacelister.OIDCOptions{
+				Issuers: []namespacelister.OIDCIssuerOptions{o},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("authenticates valid tokens", func(ctx context.Context) {
+			// given
+			token := newToken(validClaims(url))
+
+			// when
+			rs, ok, err := authenticate(ctx, auth, token)
+
+			// then
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(rs.User.GetName()).To(Equal("oidc:1234"))
+		})
+
+		It("rejects tokens signed with unknown keys", func(ctx context.Context) {
+			// given
+			var err error
+			key, err = rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+			token := newToken(validClaims(url))
+
+			// when
+			_, ok, err := authenticate(ctx, auth, token)
+
+			// then
+			Expect(err).To(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	It("is chained ahead of TokenReviews", func(ctx context.Context) {
+		// given
+		o := issuerOptions(issuer.URL)
+		o.JWKSFile = writeJWKSFile()
+		// no TokenReview is expected
+		c := mocks.NewMockFakeInterface(gomock.NewController(GinkgoT()))
+		auth, err := namespacelister.NewAuthenticator(ctx, namespacelister.AuthenticatorOptions{
+			Client: c,
+			OIDC:   &namespacelister.OIDCOptions{Issuers: []namespacelister.OIDCIssuerOptions{o}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		token := newToken(validClaims(issuer.URL))
+
+		// when
+		rs, ok, err := authenticate(ctx, auth, token)
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(rs.User.GetName()).To(Equal("oidc:1234"))
+		Expect(rs.User.GetGroups()).To(ContainElement("system:authenticated"))
+	})
+
+	DescribeTable("requires the prefix of mapped claims", func(ctx context.Context, unset func(*namespacelister.OIDCClaimMappings)) {
+		// given
+		o := issuerOptions(issuer.URL)
+		o.JWKSFile = writeJWKSFile()
+		unset(&o.ClaimMappings)
+
+		// when
+		_, err := namespacelister.NewOIDCAuthenticator(ctx, namespacelister.OIDCOptions{
+			Issuers: []namespacelister.OIDCIssuerOptions{o},
+		})
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("prefix is required")))
+	},
+		Entry("for usernames", func(m *namespacelister.OIDCClaimMappings) { m.Username.Prefix = nil }),
+		Entry("for groups", func(m *namespacelister.OIDCClaimMappings) { m.Groups.Prefix = nil }),
+	)
+
+	It("allows to disable prefixing with an empty prefix", func(ctx context.Context) {
+		// given
+		o := issuerOptions(issuer.URL)
+		o.JWKSFile = writeJWKSFile()
+		o.ClaimMappings.Username.Prefix = ptr.To("")
+		auth, err := namespacelister.NewOIDCAuthenticator(ctx, namespacelister.OIDCOptions{
+			Issuers: []namespacelister.OIDCIssuerOptions{o},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// when
+		rs, ok, err := authenticate(ctx, auth, newToken(validClaims(issuer.URL)))
+
+		// then
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(rs.User.GetName()).To(Equal("1234"))
+	})
+
+	It("requires at least one issuer", func(ctx context.Context) {
+		// when
+		_, err := namespacelister.NewOIDCAuthenticator(ctx, namespacelister.OIDCOptions{})
+
+		// then
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -15,6 +15,7 @@ import (
 
 	"github.com/konflux-ci/namespace-lister/internal/contextkey"
 	"github.com/konflux-ci/namespace-lister/internal/resourcecache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 	"github.com/konflux-ci/namespace-lister/pkg/metricsutil"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -88,10 +90,11 @@ var _ = Describe("Authorizing requests", Serial, Ordered, Label("perf"), func()
 		// create cache, namespacelister, and handler
 		cache, err := resourcecache.BuildAndStart(ctx, cacheCfg)
 		utilruntime.Must(err)
-		c, err := buildAndStartSynchronizedAccessCache(ctx, cache, nil)
+		cc, err := buildSynchronizedAccessCaches(ctx, cache, nil)
 		utilruntime.Must(err)
+		utilruntime.Must(startSynchronizedAccessCaches(ctx, cc))
 
-		nl := NewSubjectNamespaceLister(c)
+		nl := NewSubjectNamespaceLister(cc[resourcecache.DefaultAccessProfileName])
 		lnh := NewListNamespacesHandler(nl)
 
 		// we sample a function repeatedly to get a statistically significant set of measurements
@@ -144,8 +147,10 @@ var _ = Describe("Authorizing requests", Serial, Ordered, Label("perf"), func()
 		resourceCache, err := resourcecache.BuildAndStart(ctx, cacheCfg)
 		utilruntime.Must(err)
 		registry := prometheus.NewRegistry()
-		c, err := buildAndStartSynchronizedAccessCache(ctx, resourceCache, registry)
+		cc, err := buildSynchronizedAccessCaches(ctx, resourceCache, registry)
 		utilruntime.Must(err)
+		utilruntime.Must(startSynchronizedAccessCaches(ctx, cc))
+		c := cc[resourcecache.DefaultAccessProfileName]
 
 		// check cache is correctly populated with
 		{ // the expected number of subjects
@@ -195,6 +200,49 @@ var _ = Describe("Authorizing requests", Serial, Ordered, Label("perf"), func()
 		// and assert that it is below a threshold
 		Expect(medianDuration).To(BeNumerically("<=", 200*time.Millisecond))
 	})
+
+	DescribeTable("efficiently synchronizes access cache", Serial, func(ctx context.Context, concurrency int) {
+		// new gomega experiment
+		experiment := gmeasure.NewExperiment(fmt.Sprintf("Access Cache Synch with %d workers", concurrency))
+
+		// Register the experiment as a ReportEntry - this will cause Ginkgo's reporter infrastructure
+		// to print out the experiment's report and to include the experiment in any generated reports
+		AddReportEntry(experiment.Name, experiment)
+
+		// create resourceCache and access cache
+		resourceCache, err := resourcecache.BuildAndStart(ctx, cacheCfg)
+		utilruntime.Must(err)
+		aur := &CRAuthRetriever{resourceCache}
+		c := cache.NewSynchronizedAccessCache(
+			rbac.NewSubjectAccessEvaluator(aur, aur, aur, aur, ""),
+			resourceCache,
+			cache.CacheSynchronizerOptions{Concurrency: concurrency},
+		)
+
+		// we sample a function repeatedly to get a statistically significant set of measurements
+		experiment.Sample(func(idx int) {
+			var err error
+
+			// measure Synch
+			experiment.MeasureDuration("cache synch", func() {
+				err = c.Synch(ctx)
+			})
+
+			// check results
+			if err != nil {
+				panic(err)
+			}
+		}, gmeasure.SamplingConfig{N: 30, Duration: 2 * time.Minute})
+		// we'll sample the function up to 30 times or up to 2 minutes, whichever comes first.
+
+		// check cache is correctly populated
+		Expect(c.List(rbacv1.Subject{Kind: "User", APIGroup: rbacv1.GroupName, Name: username})).To(HaveLen(len(ans)))
+	},
+		Entry("sequentially", 1),
+		Entry("with 4 workers", 4),
+		Entry("with 8 workers", 8),
+		Entry("with 16 workers", 16),
+	)
 })
 
 func createResources(ctx context.Context, cli client.Client, user string, numAllowedNamespaces, numUnallowedNamespaces, numNonMatchingClusterRoles int) ([]client.Object, []client.Object, error) {
@@ -0,0 +1,20 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewAccessibleNamespace builds an AccessibleNamespace out of a Namespace
+func NewAccessibleNamespace(ns *corev1.Namespace) *AccessibleNamespace {
+	return &AccessibleNamespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AccessibleNamespace",
+			APIVersion: SchemeGroupVersion.String(),
+		},
+		ObjectMeta: *ns.ObjectMeta.DeepCopy(),
+		Status: AccessibleNamespaceStatus{
+			Phase: ns.Status.Phase,
+		},
+	}
+}
@@ -0,0 +1,10 @@
+// Package v1 contains the v1 version of the namespacelister.konflux-ci.dev API group,
+// served by the Namespace-Lister when running as an aggregated API server.
+//
+// +k8s:deepcopy-gen=package
+// +k8s:openapi-gen=true
+// +groupName=namespacelister.konflux-ci.dev
+package v1
+
+//go:generate deepcopy-gen --go-header-file=../../../../hack/boilerplate.go.txt --output-file=zz_generated.deepcopy.go .
+//go:generate openapi-gen --go-header-file=../../../../hack/boilerplate.go.txt --output-dir=../../../generated/openapi --output-pkg=github.com/konflux-ci/namespace-lister/pkg/generated/openapi --output-file=zz_generated.openapi.go --report-filename=/dev/null . k8s.io/apimachinery/pkg/apis/meta/v1 k8s.io/apimachinery/pkg/runtime k8s.io/apimachinery/pkg/version
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AccessibleNamespace is a Namespace the requesting user has access to
+type AccessibleNamespace struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata, as of the Namespace.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Status describes the current status of the Namespace.
+	// +optional
+	Status AccessibleNamespaceStatus `json:"status,omitempty"`
+}
+
+// AccessibleNamespaceStatus is information about the current status of an AccessibleNamespace
+type AccessibleNamespaceStatus struct {
+	// Phase is the current lifecycle phase of the Namespace.
+	// +optional
+	Phase corev1.NamespacePhase `json:"phase,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AccessibleNamespaceList is a list of the Namespaces the requesting user has access to
+type AccessibleNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of AccessibleNamespaces.
+	Items []AccessibleNamespace `json:"items"`
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+// AccessAttributes identifies the action a subject needs to be allowed to perform
+// in a namespace for the namespace to be considered accessible by the subject
+type AccessAttributes struct {
+	Verb     string
+	APIGroup string
+	Resource string
+}
+
+// DefaultAccessAttributes considers a namespace accessible by the subjects allowed to get it
+var DefaultAccessAttributes = AccessAttributes{
+	Verb:     "get",
+	APIGroup: corev1.GroupName,
+	Resource: "namespaces",
+}
+
+// Validate returns an error if the attributes can not identify an action
+func (a AccessAttributes) Validate() error {
+	switch {
+	case a.Verb == "":
+		return errors.New("invalid access attributes: verb is required")
+	case a.Resource == "":
+		return errors.New("invalid access attributes: resource is required")
+	default:
+		return nil
+	}
+}
+
+// String returns the attributes in the `verb resource.group` form
+func (a AccessAttributes) String() string {
+	if a.APIGroup == "" {
+		return a.Verb + " " + a.Resource
+	}
+	return a.Verb + " " + a.Resource + "." + a.APIGroup
+}
+
+// AttributesRecordFor builds the authorization attributes for performing the action in the namespace.
+// The request targets the namespace itself when the resource is the core namespaces one.
+func (a AccessAttributes) AttributesRecordFor(namespace string) authorizer.AttributesRecord {
+	ar := authorizer.AttributesRecord{
+		Verb:            a.Verb,
+		Resource:        a.Resource,
+		APIGroup:        a.APIGroup,
+		Namespace:       namespace,
+		ResourceRequest: true,
+	}
+	if a.isNamespaces() {
+		ar.APIVersion = corev1.SchemeGroupVersion.Version
+		ar.Name = namespace
+	}
+	return ar
+}
+
+func (a AccessAttributes) isNamespaces() bool {
+	return a.APIGroup == corev1.GroupName && a.Resource == "namespaces"
+}
@@ -1,12 +1,12 @@
 package cache
 
 import (
+	"sync/atomic"
+
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
 
-type AccessData map[rbacv1.Subject][]corev1.Namespace
-
 // AccessCache represents a cache that can list namespaces a subject has access to.
 // Data in the cache can be updated via the Restock method.
 type AccessCache interface {
@@ -16,8 +16,56 @@ type AccessCache interface {
 	Restock(data *AccessData)
 }
 
-// NewAtomicListRestockAccessCache builds an AccessCache leveraging on the AtomicListRestockCache
+var _ AccessCache = &AtomicAccessDataCache{}
+
+// AtomicAccessDataCache is an AccessCache that stores the AccessData behind an atomic.Pointer.
+// Namespaces are stored once and their virtual labels and annotations are materialized on List.
+type AtomicAccessDataCache struct {
+	data atomic.Pointer[AccessData]
+}
+
+// NewAtomicAccessDataCache builds an AtomicAccessDataCache
+func NewAtomicAccessDataCache() *AtomicAccessDataCache {
+	return &AtomicAccessDataCache{}
+}
+
+// List lists all the namespaces one or more subjects have access to
+func (c *AtomicAccessDataCache) List(subjects ...rbacv1.Subject) []corev1.Namespace {
+	return c.data.Load().List(subjects...)
+}
+
+// Restock fully replaces data stored in the cache
+func (c *AtomicAccessDataCache) Restock(data *AccessData) {
+	c.data.Store(data)
+}
+
+// subjectNamespaces maps each subject to the namespaces it has access to
+type subjectNamespaces map[rbacv1.Subject][]corev1.Namespace
+
+// atomicListRestockAccessCache adapts the AtomicListRestockCache to the AccessCache interface
+type atomicListRestockAccessCache struct {
+	*AtomicListRestockCache[rbacv1.Subject, []corev1.Namespace, corev1.Namespace, subjectNamespaces, string]
+}
+
+// NewAtomicListRestockAccessCache builds an AccessCache leveraging on the AtomicListRestockCache.
+// Namespaces are materialized on Restock, storing a copy of each namespace for each subject having access to it.
 func NewAtomicListRestockAccessCache() AccessCache {
-	return newAtomicListRestockCache[rbacv1.Subject, []corev1.Namespace, AccessData](
-		func(n corev1.Namespace) string { return n.GetName() })
+	return &atomicListRestockAccessCache{
+		newAtomicListRestockCache[rbacv1.Subject, []corev1.Namespace, subjectNamespaces](
+			func(n corev1.Namespace) string { return n.GetName() }),
+	}
+}
+
+// Restock materializes the namespaces of each subject and fully replaces data stored in the cache
+func (c *atomicListRestockAccessCache) Restock(data *AccessData) {
+	if data == nil {
+		c.AtomicListRestockCache.Restock(nil)
+		return
+	}
+
+	m := make(subjectNamespaces, len(data.subjects))
+	for sub := range data.subjects {
+		m[sub] = data.List(sub)
+	}
+	c.AtomicListRestockCache.Restock(&m)
 }
@@ -0,0 +1,121 @@
+package cache_test
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+)
+
+const (
+	memoryNamespaces          = 5000
+	memoryUsers               = 1000
+	memoryNamespacesPerUser   = 20
+	memorySystemAuthenticated = "system:authenticated"
+)
+
+var accessCaches = map[string]func() cache.AccessCache{
+	"AtomicListRestockAccessCache": cache.NewAtomicListRestockAccessCache,
+	"AtomicAccessDataCache":        func() cache.AccessCache { return cache.NewAtomicAccessDataCache() },
+}
+
+// buildMemoryAccessData builds the AccessData of a cluster whose namespaces are all accessible
+// by the system:authenticated group, while each user has access to a few of them
+func buildMemoryAccessData() *cache.AccessData {
+	authenticated := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: memorySystemAuthenticated}
+
+	nn := make([]corev1.Namespace, memoryNamespaces)
+	for i := range nn {
+		n := fmt.Sprintf("tenant-%d", i)
+		nn[i] = corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:            n,
+			ResourceVersion: fmt.Sprint(i),
+			Labels: map[string]string{
+				"kubernetes.io/metadata.name":   n,
+				"konflux-ci.dev/type":           "tenant",
+				cache.VirtualLabelKeyVisibility: cache.VirtualLabelValueVisibilityAuthenticated,
+			},
+			Annotations: map[string]string{"openshift.io/display-name": n},
+		}}
+	}
+
+	d := &cache.AccessData{}
+	for i := range nn {
+		d.Add(authenticated, nn[i])
+	}
+	for u := range memoryUsers {
+		sub := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: fmt.Sprintf("user-%d", u)}
+		for i := range memoryNamespacesPerUser {
+			d.Add(sub, nn[(u*memoryNamespacesPerUser+i)%len(nn)])
+		}
+	}
+	return d
+}
+
+// retainedHeap returns the heap retained by the value built by the provided function
+func retainedHeap(build func() any) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	v := build()
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+
+	if after.HeapAlloc < before.HeapAlloc {
+		return 0
+	}
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+// restockedCache builds the access cache and restocks it with fresh data
+func restockedCache(newCache func() cache.AccessCache) func() any {
+	return func() any {
+		c := newCache()
+		c.Restock(buildMemoryAccessData())
+		return c
+	}
+}
+
+// BenchmarkAccessCacheRestock reports the heap retained by each access cache once restocked
+func BenchmarkAccessCacheRestock(b *testing.B) {
+	for name, newCache := range accessCaches {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			var retained uint64
+			for b.Loop() {
+				retained += retainedHeap(restockedCache(newCache))
+			}
+			b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+		})
+	}
+}
+
+// BenchmarkAccessCacheList measures listing the namespaces of a user and its groups,
+// including the materialization of the virtual labels and annotations
+func BenchmarkAccessCacheList(b *testing.B) {
+	subjects := []rbacv1.Subject{
+		{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "user-0"},
+		{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: memorySystemAuthenticated},
+	}
+
+	for name, newCache := range accessCaches {
+		b.Run(name, func(b *testing.B) {
+			c := newCache()
+			c.Restock(buildMemoryAccessData())
+			b.ReportAllocs()
+
+			for b.Loop() {
+				c.List(subjects...)
+			}
+		})
+	}
+}
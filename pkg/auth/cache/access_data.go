@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// NamespaceID identifies a namespace stored in an AccessData
+type NamespaceID uint32
+
+// AccessData stores the namespaces each subject has access to.
+//
+// Each namespace is stored once, without the virtual labels and annotations depending on the subject,
+// while subjects reference the namespaces they have access to by ID.
+// Memory grows with the number of namespaces plus the number of (subject, namespace) pairs times
+// the size of a NamespaceID, rather than with the pairs times the size of a namespace.
+// Virtual labels and annotations are materialized when namespaces are listed.
+//
+// The zero value is an empty AccessData ready to use.
+// An AccessData must not be modified once it has been used to restock a cache.
+type AccessData struct {
+	namespaces []corev1.Namespace
+	ids        map[string]NamespaceID
+	subjects   map[rbacv1.Subject][]NamespaceID
+}
+
+// Add records that the subject has access to the namespace.
+// Namespaces are identified by name: only the first namespace added with a given name is stored.
+func (d *AccessData) Add(sub rbacv1.Subject, ns corev1.Namespace) {
+	id := d.intern(ns)
+
+	if d.subjects == nil {
+		d.subjects = map[rbacv1.Subject][]NamespaceID{}
+	}
+	d.subjects[sub] = insertID(d.subjects[sub], id)
+}
+
+// intern stores the namespace if not already present and returns its ID
+func (d *AccessData) intern(ns corev1.Namespace) NamespaceID {
+	if id, ok := d.ids[ns.GetName()]; ok {
+		return id
+	}
+
+	if d.ids == nil {
+		d.ids = map[string]NamespaceID{}
+	}
+	id := NamespaceID(len(d.namespaces))
+	d.namespaces = append(d.namespaces, withoutVirtualLabelsAndAnnotationsForAccess(ns))
+	d.ids[ns.GetName()] = id
+	return id
+}
+
+// insertID adds the ID to the sorted set of IDs.
+// Namespaces are usually added in ID order, so the ID is appended in the common case.
+func insertID(ids []NamespaceID, id NamespaceID) []NamespaceID {
+	if len(ids) == 0 || ids[len(ids)-1] < id {
+		return append(ids, id)
+	}
+
+	i, found := slices.BinarySearch(ids, id)
+	if found {
+		return ids
+	}
+	return slices.Insert(ids, i, id)
+}
+
+// Len returns the number of subjects having access to any namespace
+func (d *AccessData) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.subjects)
+}
+
+// Pairs returns the number of (subject, namespace) pairs
+func (d *AccessData) Pairs() int {
+	if d == nil {
+		return 0
+	}
+
+	n := 0
+	for _, ids := range d.subjects {
+		n += len(ids)
+	}
+	return n
+}
+
+// List lists all the namespaces one or more subjects have access to.
+// Each namespace is returned once, with the virtual labels and annotations of the first
+// of the provided subjects having access to it.
+//
+// Returned namespaces share all but labels and annotations with the AccessData, so they must not be modified.
+func (d *AccessData) List(subjects ...rbacv1.Subject) []corev1.Namespace {
+	if d == nil || len(subjects) == 0 {
+		return nil
+	}
+
+	if len(subjects) == 1 {
+		ids := d.subjects[subjects[0]]
+		if len(ids) == 0 {
+			return nil
+		}
+
+		nn := make([]corev1.Namespace, 0, len(ids))
+		for _, id := range ids {
+			nn = append(nn, withVirtualLabelsAndAnnotationsForAccess(d.namespaces[id], subjects[0]))
+		}
+		return nn
+	}
+
+	// worst case scenario: namespaces from provided subjects are different among them
+	maxSize := 0
+	for _, sub := range subjects {
+		maxSize += len(d.subjects[sub])
+	}
+
+	// keep track of the namespaces already listed, so we'll avoid duplicates
+	seen := make(map[NamespaceID]struct{}, maxSize)
+	nn := make([]corev1.Namespace, 0, maxSize)
+	for _, sub := range subjects {
+		for _, id := range d.subjects[sub] {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			nn = append(nn, withVirtualLabelsAndAnnotationsForAccess(d.namespaces[id], sub))
+		}
+	}
+
+	// remove exceeding capacity and return the list
+	return slices.Clip(nn)
+}
+
+// Get retrieves the namespace with the provided name if any of the subjects has access to it.
+// The namespace is returned with the virtual labels and annotations of the first
+// of the provided subjects having access to it, as List does.
+//
+// The returned namespace shares all but labels and annotations with the AccessData, so it must not be modified.
+func (d *AccessData) Get(name string, subjects ...rbacv1.Subject) (corev1.Namespace, bool) {
+	if d == nil {
+		return corev1.Namespace{}, false
+	}
+
+	id, ok := d.ids[name]
+	if !ok {
+		return corev1.Namespace{}, false
+	}
+
+	for _, sub := range subjects {
+		if _, found := slices.BinarySearch(d.subjects[sub], id); found {
+			return withVirtualLabelsAndAnnotationsForAccess(d.namespaces[id], sub), true
+		}
+	}
+	return corev1.Namespace{}, false
+}
+
+// withoutNamespaces returns a copy of the AccessData without the given namespaces.
+// The remaining namespaces keep their relative order, so that the sets of IDs stay sorted.
+func (d *AccessData) withoutNamespaces(names map[string]struct{}) *AccessData {
+	c := &AccessData{
+		namespaces: make([]corev1.Namespace, 0, len(d.namespaces)),
+		ids:        make(map[string]NamespaceID, len(d.ids)),
+		subjects:   make(map[rbacv1.Subject][]NamespaceID, len(d.subjects)),
+	}
+
+	// map the IDs of the retained namespaces to their new IDs
+	kept := make([]bool, len(d.namespaces))
+	remap := make([]NamespaceID, len(d.namespaces))
+	for id, ns := range d.namespaces {
+		if _, ok := names[ns.GetName()]; ok {
+			continue
+		}
+
+		kept[id], remap[id] = true, NamespaceID(len(c.namespaces))
+		c.ids[ns.GetName()] = remap[id]
+		c.namespaces = append(c.namespaces, ns)
+	}
+
+	for sub, ids := range d.subjects {
+		nids := make([]NamespaceID, 0, len(ids))
+		for _, id := range ids {
+			if kept[id] {
+				nids = append(nids, remap[id])
+			}
+		}
+		if len(nids) > 0 {
+			c.subjects[sub] = nids
+		}
+	}
+	return c
+}
+
+// subjectNamespaceNames returns the names of the namespaces each subject has access to
+func (d *AccessData) subjectNamespaceNames() map[rbacv1.Subject][]string {
+	if d == nil {
+		return nil
+	}
+
+	r := make(map[rbacv1.Subject][]string, len(d.subjects))
+	for sub, ids := range d.subjects {
+		nn := make([]string, 0, len(ids))
+		for _, id := range ids {
+			nn = append(nn, d.namespaces[id].GetName())
+		}
+		r[sub] = nn
+	}
+	return r
+}
+
+// withVirtualLabelsAndAnnotationsForAccess returns a copy of the namespace with the virtual labels and annotations
+// of the access granted by the subject. Labels and annotations are copied, as the namespace is shared.
+func withVirtualLabelsAndAnnotationsForAccess(ns corev1.Namespace, sub rbacv1.Subject) corev1.Namespace {
+	// add labels
+	ll := make(map[string]string, len(ns.Labels)+1)
+	maps.Copy(ll, ns.Labels)
+	ll[VirtualLabelKeyAccess] = VirtualAccessKind(sub)
+	ns.Labels = ll
+
+	// add annotations
+	aa := make(map[string]string, len(ns.Annotations)+2)
+	maps.Copy(aa, ns.Annotations)
+	aa[VirtualAnnotationKeySubjectName] = sub.Name
+	if sub.Namespace != "" {
+		aa[VirtualAnnotationKeySubjectNamespace] = sub.Namespace
+	}
+	ns.Annotations = aa
+
+	// return copy
+	return ns
+}
+
+// withoutVirtualLabelsAndAnnotationsForAccess removes the labels and annotations
+// that depend on the subject the namespace is accessible by
+func withoutVirtualLabelsAndAnnotationsForAccess(ns corev1.Namespace) corev1.Namespace {
+	lns := ns.DeepCopy()
+	delete(lns.Labels, VirtualLabelKeyAccess)
+	delete(lns.Annotations, VirtualAnnotationKeySubjectName)
+	delete(lns.Annotations, VirtualAnnotationKeySubjectNamespace)
+	return *lns
+}
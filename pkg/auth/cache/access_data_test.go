@@ -0,0 +1,174 @@
+package cache_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+)
+
+// newAccessData builds an AccessData adding the namespaces of each subject.
+// Subjects are added sorted by name, so that namespaces' IDs are stable.
+func newAccessData(m map[rbacv1.Subject][]corev1.Namespace) *cache.AccessData {
+	d := &cache.AccessData{}
+	for _, sub := range slices.SortedFunc(maps.Keys(m), func(a, b rbacv1.Subject) int { return strings.Compare(a.Name, b.Name) }) {
+		for _, ns := range m[sub] {
+			d.Add(sub, ns)
+		}
+	}
+	return d
+}
+
+// namespacesNamed builds a namespace for each of the provided names
+func namespacesNamed(names ...string) []corev1.Namespace {
+	nn := make([]corev1.Namespace, 0, len(names))
+	for _, n := range names {
+		nn = append(nn, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: n}})
+	}
+	return nn
+}
+
+var _ = Describe("AccessData", func() {
+	ns := func(name string) corev1.Namespace {
+		return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"key": "value"},
+			Annotations: map[string]string{"key": "value"},
+		}}
+	}
+
+	It("lists nothing if empty", func() {
+		// given
+		var nilData *cache.AccessData
+
+		// then
+		Expect(nilData.List(userSubject)).To(BeEmpty())
+		Expect(nilData.Len()).To(BeZero())
+		Expect((&cache.AccessData{}).List(userSubject)).To(BeEmpty())
+	})
+
+	It("materializes the virtual labels and annotations of the subject", func() {
+		// given
+		d := &cache.AccessData{}
+		d.Add(userSubject, namespaces[0])
+		d.Add(serviceAccountSubject, namespaces[0])
+		d.Add(groupSubject, namespaces[0])
+
+		// then
+		Expect(d.Len()).To(Equal(3))
+		Expect(d.Pairs()).To(Equal(3))
+		Expect(d.List(serviceAccountSubject)).To(HaveExactElements(
+			And(
+				HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "serviceaccount")),
+				HaveField("Annotations", HaveKeyWithValue(cache.VirtualAnnotationKeySubjectNamespace, serviceAccountSubject.Namespace)),
+			)))
+		Expect(d.List(userSubject)).To(HaveExactElements(
+			And(
+				HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "user")),
+				HaveField("Annotations", HaveKeyWithValue(cache.VirtualAnnotationKeySubjectName, userSubject.Name)),
+				HaveField("Annotations", Not(HaveKey(cache.VirtualAnnotationKeySubjectNamespace))),
+			)))
+	})
+
+	It("does not modify the stored namespaces", func() {
+		// given
+		d := &cache.AccessData{}
+		d.Add(userSubject, ns("myns"))
+		d.Add(groupSubject, ns("myns"))
+
+		// when
+		d.List(userSubject)[0].Labels["key"] = "changed"
+
+		// then
+		Expect(d.List(groupSubject)).To(HaveExactElements(
+			HaveField("Labels", HaveKeyWithValue("key", "value"))))
+	})
+
+	It("stores namespaces without the virtual labels and annotations of other subjects", func() {
+		// given
+		d := &cache.AccessData{}
+		d.Add(serviceAccountSubject, expectedNamespacesServiceAccountAccess[0])
+
+		// when
+		d.Add(userSubject, expectedNamespacesServiceAccountAccess[0])
+
+		// then
+		Expect(d.List(userSubject)).To(HaveExactElements(
+			HaveField("Annotations", Not(HaveKey(cache.VirtualAnnotationKeySubjectNamespace)))))
+	})
+
+	It("lists the namespaces in the order they have been added", func() {
+		// given
+		d := &cache.AccessData{}
+		for _, n := range []string{"a", "c", "b"} {
+			d.Add(groupSubject, ns(n))
+		}
+		d.Add(userSubject, ns("b"))
+		d.Add(userSubject, ns("a"))
+		d.Add(userSubject, ns("a"))
+
+		// then
+		Expect(d.Pairs()).To(Equal(5))
+		Expect(d.List(userSubject)).To(HaveExactElements(
+			HaveField("Name", "a"),
+			HaveField("Name", "b"),
+		))
+	})
+
+	It("gets a namespace with the labels of the first subject having access to it", func() {
+		// given
+		d := &cache.AccessData{}
+		d.Add(userSubject, ns("a"))
+		d.Add(groupSubject, ns("a"))
+		d.Add(groupSubject, ns("b"))
+
+		// when
+		a, aok := d.Get("a", serviceAccountSubject, groupSubject, userSubject)
+		b, bok := d.Get("b", userSubject, groupSubject)
+
+		// then
+		Expect(aok).To(BeTrue())
+		Expect(a).To(And(HaveField("Name", "a"), HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "group"))))
+		Expect(bok).To(BeTrue())
+		Expect(b).To(And(HaveField("Name", "b"), HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "group"))))
+	})
+
+	It("does not get namespaces the subjects have no access to", func() {
+		// given
+		var nilData *cache.AccessData
+		d := &cache.AccessData{}
+		d.Add(userSubject, ns("a"))
+		d.Add(groupSubject, ns("b"))
+
+		// then
+		Expect(nilData.Get("a", userSubject)).To(BeZero())
+		Expect(d.Get("b", userSubject)).To(BeZero())
+		Expect(d.Get("unknown", userSubject, groupSubject)).To(BeZero())
+		Expect(d.Get("a")).To(BeZero())
+	})
+
+	It("lists the namespaces of more subjects once, with the labels of the first subject", func() {
+		// given
+		d := &cache.AccessData{}
+		d.Add(userSubject, ns("a"))
+		d.Add(groupSubject, ns("a"))
+		d.Add(groupSubject, ns("b"))
+
+		// when
+		nn := d.List(userSubject, groupSubject)
+
+		// then
+		Expect(nn).To(HaveExactElements(
+			And(HaveField("Name", "a"), HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "user"))),
+			And(HaveField("Name", "b"), HaveField("Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "group"))),
+		))
+	})
+})
@@ -1,10 +1,9 @@
 package cache_test
 
 import (
-	"slices"
-
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -13,7 +12,7 @@ import (
 	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
 )
 
-var _ = Describe("AuthCache", func() {
+var _ = DescribeTableSubtree("AuthCache", func(newCache func() cache.AccessCache) {
 	enn := []corev1.Namespace{
 		{
 			ObjectMeta: metav1.ObjectMeta{
@@ -24,9 +23,17 @@ var _ = Describe("AuthCache", func() {
 		},
 	}
 
+	haveNames := func(nn ...string) types.GomegaMatcher {
+		mm := make([]any, 0, len(nn))
+		for _, n := range nn {
+			mm = append(mm, HaveField("ObjectMeta.Name", n))
+		}
+		return ConsistOf(mm...)
+	}
+
 	It("returns an empty result if it is empty", func() {
 		// given
-		emptyCache := cache.NewAtomicListRestockAccessCache()
+		emptyCache := newCache()
 
 		// when
 		nn := emptyCache.List(rbacv1.Subject{})
@@ -38,48 +45,53 @@ var _ = Describe("AuthCache", func() {
 	It("matches a subject", func() {
 		// given
 		sub := rbacv1.Subject{Kind: "User", Name: "myuser"}
-		c := cache.NewAtomicListRestockAccessCache()
-		c.Restock(&cache.AccessData{sub: enn})
+		c := newCache()
+		c.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{sub: enn}))
 
 		// when
 		nn := c.List(sub)
 
 		// then
-		Expect(nn).To(ConsistOf(enn))
+		Expect(nn).To(HaveExactElements(And(
+			HaveField("ObjectMeta.Name", "myns"),
+			HaveField("ObjectMeta.Labels", HaveKeyWithValue("key", "value")),
+			HaveField("ObjectMeta.Labels", HaveKeyWithValue(cache.VirtualLabelKeyAccess, "user")),
+			HaveField("ObjectMeta.Annotations", HaveKeyWithValue(cache.VirtualAnnotationKeySubjectName, "myuser")),
+		)))
 	})
 
 	It("matches more subjects with overlapping namespaces", func() {
 		// given
 		sub1 := rbacv1.Subject{Kind: "User", Name: "myuser1"}
 		sub2 := rbacv1.Subject{Kind: "User", Name: "myuser2"}
-		c := cache.NewAtomicListRestockAccessCache()
-		c.Restock(&cache.AccessData{
+		c := newCache()
+		c.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{
 			sub1: enn,
 			sub2: enn,
-		})
+		}))
 
 		// when
 		nn := c.List(sub1, sub2)
 
 		// then
-		Expect(nn).To(ConsistOf(enn))
+		Expect(nn).To(haveNames("myns"))
 	})
 
 	It("matches more subjects with overlapping namespaces and different subject kinds", func() {
 		// given
 		sub1 := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "myuser"}
 		sub2 := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "mygroup"}
-		c := cache.NewAtomicListRestockAccessCache()
-		c.Restock(&cache.AccessData{
+		c := newCache()
+		c.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{
 			sub1: enn,
 			sub2: enn,
-		})
+		}))
 
 		// when
 		nn := c.List(sub1, sub2)
 
 		// then
-		Expect(nn).To(ConsistOf(enn))
+		Expect(nn).To(haveNames("myns"))
 	})
 
 	It("matches more subjects with non-overlapping namespaces", func() {
@@ -88,18 +100,17 @@ var _ = Describe("AuthCache", func() {
 		nn1 := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myuser1ns"}}}
 		sub2 := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "myuser2"}
 		nn2 := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myuser2ns"}}}
-		c := cache.NewAtomicListRestockAccessCache()
-		c.Restock(&cache.AccessData{
+		c := newCache()
+		c.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{
 			sub1: nn1,
 			sub2: nn2,
-		})
+		}))
 
 		// when
 		nn := c.List(sub1, sub2)
 
 		// then
-		expectedNn := slices.Concat(nn1, nn2)
-		Expect(nn).To(ConsistOf(expectedNn))
+		Expect(nn).To(haveNames("myuser1ns", "myuser2ns"))
 	})
 
 	It("matches more subjects with non-overlapping namespaces and different subject kinds", func() {
@@ -108,17 +119,19 @@ var _ = Describe("AuthCache", func() {
 		nn1 := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "myuserns"}}}
 		sub2 := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "mygroup"}
 		nn2 := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "mygroupns"}}}
-		c := cache.NewAtomicListRestockAccessCache()
-		c.Restock(&cache.AccessData{
+		c := newCache()
+		c.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{
 			sub1: nn1,
 			sub2: nn2,
-		})
+		}))
 
 		// when
 		nn := c.List(sub1, sub2)
 
 		// then
-		expectedNn := slices.Concat(nn1, nn2)
-		Expect(nn).To(ConsistOf(expectedNn))
+		Expect(nn).To(haveNames("myuserns", "mygroupns"))
 	})
-})
+},
+	Entry("AtomicListRestockAccessCache", cache.NewAtomicListRestockAccessCache),
+	Entry("AtomicAccessDataCache", func() cache.AccessCache { return cache.NewAtomicAccessDataCache() }),
+)
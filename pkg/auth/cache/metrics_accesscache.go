@@ -23,16 +23,16 @@ type AccessCacheMetrics interface {
 	// CollectRequestMetrics collects metrics on synchronization requests
 	CollectRequestMetrics(Event, bool)
 	// CollectSynchMetrics collects metrics on synchronization runs
-	CollectSynchMetrics(float64, AccessData, error)
+	CollectSynchMetrics(float64, *AccessData, error)
 }
 
 // NoOpAccessCacheMetrics is used to disable AccessCache's metrics
 type NoOpAccessCacheMetrics struct{}
 
-func (m *NoOpAccessCacheMetrics) Collect(_ chan<- prometheus.Metric)                   {}
-func (m *NoOpAccessCacheMetrics) Describe(_ chan<- *prometheus.Desc)                   {}
-func (m *NoOpAccessCacheMetrics) CollectRequestMetrics(_ Event, _ bool)                {}
-func (m *NoOpAccessCacheMetrics) CollectSynchMetrics(_ float64, _ AccessData, _ error) {}
+func (m *NoOpAccessCacheMetrics) Collect(_ chan<- prometheus.Metric)                    {}
+func (m *NoOpAccessCacheMetrics) Describe(_ chan<- *prometheus.Desc)                    {}
+func (m *NoOpAccessCacheMetrics) CollectRequestMetrics(_ Event, _ bool)                 {}
+func (m *NoOpAccessCacheMetrics) CollectSynchMetrics(_ float64, _ *AccessData, _ error) {}
 
 // accessCacheMetrics is used to collect AccessCache's metrics
 type accessCacheMetrics struct {
@@ -171,7 +171,7 @@ func (m *accessCacheMetrics) collectResourceEventRequestMetrics(status string) {
 	m.resourceRequestsGauge.With(labels).Inc()
 }
 
-func (s *accessCacheMetrics) CollectSynchMetrics(duration float64, cacheData AccessData, err error) {
+func (s *accessCacheMetrics) CollectSynchMetrics(duration float64, cacheData *AccessData, err error) {
 	if err != nil {
 		// store synch duration
 		s.synchDuration.With(prometheus.Labels{"status": "failed"}).Observe(duration)
@@ -188,13 +188,15 @@ func (s *accessCacheMetrics) CollectSynchMetrics(duration float64, cacheData Acc
 	s.synchGauge.With(prometheus.Labels{"status": "completed", "error": ""}).Inc()
 
 	// update subjects in cache
-	s.subjectCounter.Set(float64(len(cacheData)))
+	s.subjectCounter.Set(float64(cacheData.Len()))
 
 	// update number of (subject, namespace) pairs
 	gkNsPairCount := map[string]int{}
-	for s, v := range cacheData {
-		sgk := strings.TrimLeft(s.APIGroup+"/"+s.Kind, "/")
-		gkNsPairCount[sgk] = gkNsPairCount[sgk] + len(v)
+	if cacheData != nil {
+		for s, ids := range cacheData.subjects {
+			sgk := strings.TrimLeft(s.APIGroup+"/"+s.Kind, "/")
+			gkNsPairCount[sgk] = gkNsPairCount[sgk] + len(ids)
+		}
 	}
 
 	for gk, n := range gkNsPairCount {
@@ -27,7 +27,7 @@ var _ = Describe("MetricsAccessCache/FailedSynch", func() {
 
 	It("collects failed synch metrics for empty access data", func(ctx context.Context) {
 		// when
-		metrics.CollectSynchMetrics(.0, cache.AccessData{}, errors.New("err"))
+		metrics.CollectSynchMetrics(.0, &cache.AccessData{}, errors.New("err"))
 
 		// then
 		vec, err := metricsutil.GetVector(metrics, metricsutil.SyncMetricFullname)
@@ -39,7 +39,7 @@ var _ = Describe("MetricsAccessCache/FailedSynch", func() {
 	})
 })
 
-var _ = DescribeTable("MetricsAccessCache/SuccessfulSynch", func(data cache.AccessData, err error, subNsPairs int) {
+var _ = DescribeTable("MetricsAccessCache/SuccessfulSynch", func(data *cache.AccessData, err error, subNsPairs int) {
 	// given
 	metrics := cache.NewAccessCacheMetrics()
 
@@ -60,7 +60,7 @@ var _ = DescribeTable("MetricsAccessCache/SuccessfulSynch", func(data cache.Acce
 		vec, err := metricsutil.GetVector(metrics, metricsutil.SubjectsMetricFullname)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(vec).To(HaveLen(1))
-		Expect(vec[0].Value).To(Equal(model.SampleValue(len(data))))
+		Expect(vec[0].Value).To(Equal(model.SampleValue(data.Len())))
 	}
 	// check we have registered the correct amount of (subject,namespace) pairs
 	if subNsPairs > 0 {
@@ -71,17 +71,17 @@ var _ = DescribeTable("MetricsAccessCache/SuccessfulSynch", func(data cache.Acce
 	}
 },
 	Entry("nil data", nil, nil, 0),
-	Entry("empty data", cache.AccessData{}, nil, 0),
-	Entry("1 subject", cache.AccessData{
-		rbacv1.Subject{}: []corev1.Namespace{},
-	}, nil, 0),
-	Entry("2 subjects - 10 Namespaces", cache.AccessData{
-		rbacv1.Subject{Name: "1"}: []corev1.Namespace{{}, {}, {}, {}, {}},
-		rbacv1.Subject{Name: "2"}: []corev1.Namespace{{}, {}, {}, {}, {}},
-	}, nil, 10),
+	Entry("empty data", &cache.AccessData{}, nil, 0),
+	Entry("1 subject - 1 Namespace", newAccessData(map[rbacv1.Subject][]corev1.Namespace{
+		{}: namespacesNamed("1"),
+	}), nil, 1),
+	Entry("2 subjects - 10 Namespaces", newAccessData(map[rbacv1.Subject][]corev1.Namespace{
+		{Name: "1"}: namespacesNamed("1", "2", "3", "4", "5"),
+		{Name: "2"}: namespacesNamed("6", "7", "8", "9", "10"),
+	}), nil, 10),
 )
 
-var _ = DescribeTable("MetricsAccessCache/UnsuccessfulSynch", func(data cache.AccessData, err error) {
+var _ = DescribeTable("MetricsAccessCache/UnsuccessfulSynch", func(data *cache.AccessData, err error) {
 	// given
 	metrics := cache.NewAccessCacheMetrics()
 
@@ -111,12 +111,12 @@ var _ = DescribeTable("MetricsAccessCache/UnsuccessfulSynch", func(data cache.Ac
 		Expect(vec).To(BeEmpty())
 	}
 },
-	Entry("unexpected data in cache", cache.AccessData{
-		rbacv1.Subject{Name: "1"}: []corev1.Namespace{{}, {}, {}, {}, {}},
-		rbacv1.Subject{Name: "2"}: []corev1.Namespace{{}, {}, {}, {}, {}},
-	}, errors.New("error")),
-	Entry("generic error", cache.AccessData{}, errors.New("error")),
-	Entry("context deadline exceeded error", cache.AccessData{}, errors.New("context deadline exceeded")),
+	Entry("unexpected data in cache", newAccessData(map[rbacv1.Subject][]corev1.Namespace{
+		{Name: "1"}: namespacesNamed("1", "2", "3", "4", "5"),
+		{Name: "2"}: namespacesNamed("6", "7", "8", "9", "10"),
+	}), errors.New("error")),
+	Entry("generic error", &cache.AccessData{}, errors.New("error")),
+	Entry("context deadline exceeded error", &cache.AccessData{}, errors.New("context deadline exceeded")),
 )
 
 var _ = Describe("MetricsAccessCache/TimeRequests", func() {
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// NamespaceSubjects is the inverted index of AccessData:
+// it maps the name of each namespace to the subjects having access to it
+type NamespaceSubjects map[string][]rbacv1.Subject
+
+// newNamespaceSubjects builds the inverted index of the provided AccessData.
+// Subjects are sorted by API group, kind, namespace, and name.
+func newNamespaceSubjects(data *AccessData) NamespaceSubjects {
+	ns := make(NamespaceSubjects, len(data.namespaces))
+	for sub, ids := range data.subjects {
+		for _, id := range ids {
+			n := data.namespaces[id].GetName()
+			ns[n] = append(ns[n], sub)
+		}
+	}
+	for _, ss := range ns {
+		slices.SortFunc(ss, compareSubjects)
+	}
+	return ns
+}
+
+// VirtualAccessKind returns the kind of access the subject grants,
+// as set in the access virtual label of the namespaces
+func VirtualAccessKind(sub rbacv1.Subject) string {
+	return strings.ToLower(sub.Kind)
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+const snapshotVersion string = "v1"
+
+// SnapshotStore persists snapshots of the AccessData stored in the cache
+type SnapshotStore interface {
+	// Save stores the snapshot replacing the previous one
+	Save(*Snapshot) error
+	// Load retrieves the last stored snapshot.
+	// It returns nil if no snapshot has been stored.
+	Load() (*Snapshot, error)
+}
+
+// Snapshot is a serializable copy of AccessData.
+// Namespaces are stored once, while subjects reference them by name.
+type Snapshot struct {
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+
+	// ResourceVersion is the highest resourceVersion of the namespaces in the snapshot
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	Namespaces []corev1.Namespace `json:"namespaces"`
+	Subjects   []SnapshotSubject  `json:"subjects"`
+}
+
+// SnapshotSubject lists the namespaces a subject has access to
+type SnapshotSubject struct {
+	Subject    rbacv1.Subject `json:"subject"`
+	Namespaces []string       `json:"namespaces"`
+}
+
+// NewSnapshot builds a Snapshot out of the provided AccessData
+func NewSnapshot(data *AccessData) *Snapshot {
+	s := &Snapshot{
+		Version:  snapshotVersion,
+		Time:     time.Now(),
+		Subjects: make([]SnapshotSubject, 0, data.Len()),
+	}
+
+	for sub, nn := range data.subjectNamespaceNames() {
+		s.Subjects = append(s.Subjects, SnapshotSubject{Subject: sub, Namespaces: nn})
+	}
+
+	var rv uint64
+	if data != nil {
+		for _, ns := range data.namespaces {
+			if v, err := strconv.ParseUint(ns.GetResourceVersion(), 10, 64); err == nil {
+				rv = max(rv, v)
+			}
+		}
+		s.Namespaces = slices.SortedFunc(slices.Values(data.namespaces), func(a, b corev1.Namespace) int {
+			return strings.Compare(a.GetName(), b.GetName())
+		})
+	}
+	if rv > 0 {
+		s.ResourceVersion = strconv.FormatUint(rv, 10)
+	}
+
+	// sort data so that snapshots of the same AccessData are equal
+	slices.SortFunc(s.Subjects, func(a, b SnapshotSubject) int { return compareSubjects(a.Subject, b.Subject) })
+	return s
+}
+
+var _ SnapshotStore = &FileSnapshotStore{}
+
+// FileSnapshotStore stores snapshots as JSON in a local file
+type FileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore builds a FileSnapshotStore persisting snapshots at the given path
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+// Save writes the snapshot to a temporary file and moves it to the store's path,
+// so that readers never observe a partially written snapshot.
+func (f *FileSnapshotStore) Save(s *Snapshot) error {
+	t, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(t.Name()) //nolint:errcheck
+
+	if err := json.NewEncoder(t).Encode(s); err != nil {
+		_ = t.Close()
+		return err
+	}
+	if err := t.Sync(); err != nil {
+		_ = t.Close()
+		return err
+	}
+	if err := t.Close(); err != nil {
+		return err
+	}
+	return os.Rename(t.Name(), f.path)
+}
+
+// Load reads the snapshot from the store's path.
+// It returns nil if the file does not exist.
+func (f *FileSnapshotStore) Load() (*Snapshot, error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	s := &Snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func compareSubjects(a, b rbacv1.Subject) int {
+	if c := strings.Compare(a.APIGroup, b.APIGroup); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Name, b.Name)
+}
@@ -0,0 +1,213 @@
+package cache_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache/mocks"
+)
+
+var _ = Describe("FileSnapshotStore", func() {
+	var store *cache.FileSnapshotStore
+
+	BeforeEach(func() {
+		store = cache.NewFileSnapshotStore(filepath.Join(GinkgoT().TempDir(), "snapshot.json"))
+	})
+
+	It("returns nil if no snapshot has been saved", func() {
+		Expect(store.Load()).To(BeNil())
+	})
+
+	It("loads the saved snapshot", func() {
+		s := cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate}))
+
+		Expect(store.Save(s)).To(Succeed())
+		Expect(store.Load()).To(HaveField("Subjects", Equal(s.Subjects)))
+	})
+
+	It("replaces the saved snapshot", func() {
+		Expect(store.Save(cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate})))).To(Succeed())
+		Expect(store.Save(cache.NewSnapshot(&cache.AccessData{}))).To(Succeed())
+
+		Expect(store.Load()).To(HaveField("Subjects", BeEmpty()))
+	})
+})
+
+var _ = Describe("NewSnapshot", func() {
+	It("stores namespaces once without access virtual labels and annotations", func() {
+		nn := []corev1.Namespace{*expectedNamespacesUserAccessPrivate[0].DeepCopy()}
+		nn[0].ResourceVersion = "12"
+		gnn := []corev1.Namespace{*expectedNamespacesGroupAccess[0].DeepCopy()}
+		gnn[0].ResourceVersion = "12"
+		onn := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "otherns", ResourceVersion: "9"}}}
+
+		s := cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{
+			userSubject:  append(nn, onn...),
+			groupSubject: gnn,
+		}))
+
+		Expect(s.ResourceVersion).To(Equal("12"))
+		Expect(s.Namespaces).To(HaveLen(2))
+		Expect(s.Namespaces[0].Labels).NotTo(HaveKey(cache.VirtualLabelKeyAccess))
+		Expect(s.Namespaces[0].Annotations).NotTo(HaveKey(cache.VirtualAnnotationKeySubjectName))
+		Expect(s.Subjects).To(Equal([]cache.SnapshotSubject{
+			{Subject: groupSubject, Namespaces: []string{"myns"}},
+			{Subject: userSubject, Namespaces: []string{"myns", "otherns"}},
+		}))
+	})
+})
+
+var _ = Describe("SynchronizedAccessCache snapshots", func() {
+	var ctrl *gomock.Controller
+	var subjectLocator *mocks.MockSubjectLocator
+	var namespaceLister *mocks.MockClientReader
+	var store cache.SnapshotStore
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		subjectLocator = mocks.NewMockSubjectLocator(ctrl)
+		namespaceLister = mocks.NewMockClientReader(ctrl)
+		store = cache.NewFileSnapshotStore(filepath.Join(GinkgoT().TempDir(), "snapshot.json"))
+	})
+
+	expectSynch := func(ss ...rbacv1.Subject) {
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, nn *corev1.NamespaceList, opts ...client.ListOption) error {
+				(&corev1.NamespaceList{Items: namespaces}).DeepCopyInto(nn)
+				return nil
+			}).
+			Times(1)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), gomock.Any()).
+			Return(ss, nil).
+			Times(1)
+	}
+
+	It("does not load anything if no SnapshotStore is configured", func() {
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+
+		Expect(nsc.LoadSnapshot()).To(BeFalse())
+		Expect(nsc.Generation()).To(BeZero())
+	})
+
+	It("does not load anything if no snapshot has been persisted", func() {
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{SnapshotStore: store})
+
+		Expect(nsc.LoadSnapshot()).To(BeFalse())
+		Expect(nsc.Generation()).To(BeZero())
+	})
+
+	It("does not load snapshots older than the maximum age", func() {
+		s := cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate}))
+		s.Time = time.Now().Add(-2 * time.Hour)
+		Expect(store.Save(s)).To(Succeed())
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{
+			SnapshotStore:  store,
+			SnapshotMaxAge: time.Hour,
+		})
+
+		Expect(nsc.LoadSnapshot()).To(BeFalse())
+		Expect(nsc.Generation()).To(BeZero())
+		Expect(nsc.DataTime()).To(BeZero())
+	})
+
+	It("loads snapshots younger than the maximum age", func() {
+		s := cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate}))
+		s.Time = time.Now().Add(-30 * time.Minute)
+		Expect(store.Save(s)).To(Succeed())
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{
+			SnapshotStore:  store,
+			SnapshotMaxAge: time.Hour,
+		})
+
+		Expect(nsc.LoadSnapshot()).To(BeTrue())
+		Expect(nsc.List(userSubject)).To(Equal(expectedNamespacesUserAccessPrivate))
+	})
+
+	It("restores the persisted data as stale until the first synch", func(ctx context.Context) {
+		// persist a snapshot
+		expectSynch(userSubject, serviceAccountSubject)
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{SnapshotStore: store})
+		Expect(nsc.Synch(ctx)).To(Succeed())
+		Expect(nsc.Stale()).To(BeFalse())
+
+		// restore it
+		rnsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{SnapshotStore: store})
+		Expect(rnsc.LoadSnapshot()).To(BeTrue())
+		Expect(rnsc.Stale()).To(BeTrue())
+		Expect(rnsc.LastSynchTime()).To(BeZero())
+		Expect(rnsc.DataTime()).To(BeTemporally(">=", nsc.LastSynchTime()))
+		Expect(rnsc.Generation()).To(BeNumerically("==", 1))
+		Expect(rnsc.List(userSubject)).To(Equal(expectedNamespacesUserAccessPrivate))
+		Expect(rnsc.List(serviceAccountSubject)).To(Equal(expectedNamespacesServiceAccountAccess))
+
+		// synch with live data
+		expectSynch(groupSubject)
+		Expect(rnsc.Synch(ctx)).To(Succeed())
+		Expect(rnsc.Stale()).To(BeFalse())
+		Expect(rnsc.DataTime()).To(Equal(rnsc.LastSynchTime()))
+		Expect(rnsc.List(userSubject)).To(BeEmpty())
+		Expect(store.Load()).To(HaveField("Subjects", ConsistOf(HaveField("Subject", groupSubject))))
+	})
+
+	It("does not persist partial synchronizations", func(ctx context.Context) {
+		// persist a snapshot
+		expectSynch(userSubject)
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{SnapshotStore: store})
+		Expect(nsc.Synch(ctx)).To(Succeed())
+
+		// synch a namespace
+		namespaceLister.EXPECT().
+			Get(gomock.Any(), client.ObjectKey{Name: "myns"}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ client.ObjectKey, obj *corev1.Namespace, opts ...client.GetOption) error {
+				namespaces[0].DeepCopyInto(obj)
+				return nil
+			}).
+			Times(1)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), gomock.Any()).
+			Return([]rbacv1.Subject{groupSubject}, nil).
+			Times(1)
+
+		Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+		Expect(nsc.List(groupSubject)).NotTo(BeEmpty())
+		Expect(store.Load()).To(HaveField("Subjects", ConsistOf(HaveField("Subject", userSubject))))
+	})
+
+	It("does not persist stale data", func(ctx context.Context) {
+		Expect(store.Save(cache.NewSnapshot(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate})))).To(Succeed())
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{SnapshotStore: store})
+		Expect(nsc.LoadSnapshot()).To(BeTrue())
+
+		namespaceLister.EXPECT().
+			Get(gomock.Any(), client.ObjectKey{Name: "myns"}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ client.ObjectKey, obj *corev1.Namespace, opts ...client.GetOption) error {
+				namespaces[0].DeepCopyInto(obj)
+				return nil
+			}).
+			Times(1)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), gomock.Any()).
+			Return([]rbacv1.Subject{groupSubject}, nil).
+			Times(1)
+
+		Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+		Expect(nsc.Stale()).To(BeTrue())
+		Expect(nsc.LastSynchTime()).To(BeZero())
+		Expect(store.Load()).To(HaveField("Subjects", ConsistOf(HaveField("Subject", userSubject))))
+	})
+})
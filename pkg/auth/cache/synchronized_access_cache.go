@@ -3,16 +3,19 @@ package cache
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apiserver/pkg/authorization/authorizer"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -46,6 +49,22 @@ type SynchronizedAccessCache struct {
 	synchronizing atomic.Bool
 	once          sync.Once
 
+	pendingMu         sync.Mutex
+	pendingFullSynch  bool
+	pendingNamespaces map[string]struct{}
+
+	restockMu  sync.RWMutex
+	restocked  chan struct{}
+	generation uint64
+	data       *AccessData
+	subjects   NamespaceSubjects
+	stale      bool
+	lastSynch  time.Time
+	restoredAt time.Time
+
+	snapshotStore  SnapshotStore
+	snapshotMaxAge time.Duration
+
 	subjectLocator  rbac.SubjectLocator
 	namespaceLister client.Reader
 
@@ -53,6 +72,8 @@ type SynchronizedAccessCache struct {
 	syncErrorHandler func(context.Context, error, *SynchronizedAccessCache)
 	resyncPeriod     time.Duration
 	synchTimeout     time.Duration
+	concurrency      int
+	accessAttributes AccessAttributes
 
 	metrics AccessCacheMetrics
 }
@@ -65,8 +86,9 @@ func NewSynchronizedAccessCache(
 	opts CacheSynchronizerOptions,
 ) *SynchronizedAccessCache {
 	return opts.Apply(&SynchronizedAccessCache{
-		AccessCache: NewAtomicListRestockAccessCache(),
+		AccessCache: NewAtomicAccessDataCache(),
 		requested:   make(chan struct{}, 1),
+		restocked:   make(chan struct{}),
 
 		subjectLocator:  subjectLocator,
 		namespaceLister: namespaceLister,
@@ -75,19 +97,45 @@ func NewSynchronizedAccessCache(
 
 // Synch recalculates the data to be stored in the cache and applies
 func (s *SynchronizedAccessCache) Synch(ctx context.Context) error {
+	return s.runSynch(ctx, "SynchronizedAccessCache.Synch", func(ctx context.Context) (*AccessData, error) {
+		// a full synchronization covers the pending requests
+		full, nn := s.dequeue()
+		c, err := s.synch(ctx)
+		if err != nil {
+			s.requeue(full, nn)
+		}
+		return c, err
+	})
+}
+
+// SynchNamespaces recalculates the access data of the given namespaces only
+// and patches the data stored in the cache.
+// If the cache has never been synchronized, a full synchronization is run instead.
+func (s *SynchronizedAccessCache) SynchNamespaces(ctx context.Context, namespaces ...string) error {
+	return s.runSynch(ctx, "SynchronizedAccessCache.SynchNamespaces", func(ctx context.Context) (*AccessData, error) {
+		return s.synchNamespaces(ctx, namespaces)
+	})
+}
+
+// runSynch runs the synch operation tracing it in a span with the provided name
+func (s *SynchronizedAccessCache) runSynch(ctx context.Context, name string, synch func(context.Context) (*AccessData, error)) (err error) {
 	if !s.synchronizing.CompareAndSwap(false, true) {
 		// already running a synch operation
 		return ErrSynchAlreadyRunning
 	}
 	defer s.synchronizing.Store(false)
 
+	ctx, span := startSpan(ctx, name)
+	defer func() { endSpan(span, err) }()
+
 	st := time.Now()
 	// add timeout for the synch operation
 	sctx, cancel := context.WithTimeout(ctx, s.synchTimeout)
 	defer cancel()
 
 	// execute synch operation
-	cacheData, err := s.synch(sctx)
+	cacheData, err := synch(sctx)
+	span.SetAttributes(attribute.Int(attributeSubjects, cacheData.Len()))
 
 	// collect metrics wrt to synch operation result
 	d := time.Since(st).Milliseconds()
@@ -96,58 +144,367 @@ func (s *SynchronizedAccessCache) Synch(ctx context.Context) error {
 	return err
 }
 
-func (s *SynchronizedAccessCache) synch(ctx context.Context) (AccessData, error) {
+func (s *SynchronizedAccessCache) synch(ctx context.Context) (*AccessData, error) {
 	s.logger.Debug("start synchronization")
+	st := time.Now()
 	nn := corev1.NamespaceList{}
 	if err := s.namespaceLister.List(ctx, &nn); err != nil {
 		return nil, err
 	}
-
-	c := AccessData{}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int(attributeNamespaces, len(nn.Items)))
 
 	// get subjects for each namespace
-	for _, ns := range nn.Items {
+	c := &AccessData{}
+	ns, err := s.calculateAccessData(ctx, nn.Items, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// restock the cache with fresh data
+	s.restockWithStaleness(c, ns, false)
+	s.markSynchronized(st)
+
+	// persist the new data.
+	// Only full synchronizations are persisted, as partial ones run for every event
+	// on namespaced resources and would rewrite the whole snapshot each time.
+	s.saveSnapshot(c)
+
+	return c, nil
+}
+
+func (s *SynchronizedAccessCache) synchNamespaces(ctx context.Context, namespaces []string) (*AccessData, error) {
+	// patching requires the data from a previous synch
+	s.restockMu.RLock()
+	current := s.data
+	s.restockMu.RUnlock()
+	if current == nil {
+		return s.synch(ctx)
+	}
+
+	s.logger.Debug("start partial synchronization", "namespaces", len(namespaces))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int(attributeNamespaces, len(namespaces)))
+	affected := make(map[string]struct{}, len(namespaces))
+	for _, n := range namespaces {
+		affected[n] = struct{}{}
+	}
+
+	// retrieve the affected namespaces
+	nn := make([]corev1.Namespace, 0, len(affected))
+	for n := range affected {
+		ns := corev1.Namespace{}
+		if err := s.namespaceLister.Get(ctx, client.ObjectKey{Name: n}, &ns); err != nil {
+			if kerrors.IsNotFound(err) {
+				// namespace has been deleted, it only needs to be removed
+				continue
+			}
+			return nil, err
+		}
+		nn = append(nn, ns)
+	}
+
+	// build the new data without the affected namespaces.
+	// The current data is shared with readers, so it is never modified in place.
+	c := current.withoutNamespaces(affected)
+
+	// add the recalculated access data for the affected namespaces
+	ns, err := s.calculateAccessData(ctx, nn, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// restock the cache, updating the subjects of the affected namespaces only
+	s.restockNamespaces(c, affected, ns)
+
+	return c, nil
+}
+
+// calculateAccessData calculates the subjects having access to each of the given namespaces
+// and adds them to the provided data.
+// It returns the subjects of the given namespaces, indexed by namespace.
+// Namespaces are evaluated concurrently by a pool of workers, whose results are
+// merged following the order of the provided namespaces.
+func (s *SynchronizedAccessCache) calculateAccessData(ctx context.Context, nn []corev1.Namespace, data *AccessData) (NamespaceSubjects, error) {
+	rr := make([][]rbacv1.Subject, len(nn))
+
+	// start workers
+	ii := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(s.concurrency, len(nn)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ii {
+				rr[i] = s.allowedSubjects(ctx, &nn[i])
+			}
+		}()
+	}
+
+	// dispatch namespaces to workers
+	for i := range nn {
 		// interrupt if context elapsed
-		if err := ctx.Err(); err != nil {
-			s.logger.Warn("cache restocking: could not complete calculate access data process", "error", err)
-			return AccessData{}, ctx.Err()
+		if ctx.Err() != nil {
+			break
 		}
 
-		ar := authorizer.AttributesRecord{
-			Verb:            "get",
-			Resource:        "namespaces",
-			APIGroup:        corev1.GroupName,
-			APIVersion:      corev1.SchemeGroupVersion.Version,
-			Name:            ns.GetName(),
-			Namespace:       ns.GetName(),
-			ResourceRequest: true,
+		select {
+		case <-ctx.Done():
+		case ii <- i:
 		}
+	}
+	close(ii)
+	wg.Wait()
 
-		ss, err := s.subjectLocator.AllowedSubjects(ctx, ar)
-		if err != nil {
-			// do not forward the error as it should be due
-			// to cache evicted (cluster)roles
-			s.logger.Debug("cache restocking: error caculating allowed subjects", "namespace", ns.GetName(), "error", err)
+	if err := ctx.Err(); err != nil {
+		s.logger.Warn("cache restocking: could not complete calculate access data process", "error", err)
+		return nil, err
+	}
+
+	// merge results.
+	// Subjects are already sorted, as duplicates have been removed.
+	ns := make(NamespaceSubjects, len(nn))
+	for i, ss := range rr {
+		if len(ss) > 0 {
+			ns[nn[i].GetName()] = ss
+		}
+		for _, sub := range ss {
+			data.Add(sub, nn[i])
 		}
+	}
+	return ns, nil
+}
 
-		// remove duplicates from allowed subjects
-		ss = s.removeDuplicateSubjects(ss)
+// allowedSubjects calculates the subjects allowed to perform the configured action in the namespace
+// and sets the namespace's visibility virtual label accordingly
+func (s *SynchronizedAccessCache) allowedSubjects(ctx context.Context, ns *corev1.Namespace) []rbacv1.Subject {
+	ar := s.accessAttributes.AttributesRecordFor(ns.GetName())
 
-		// enforce visibility label
-		s.setVisibilityVirtualLabel(&ns, ss)
+	ss, err := s.subjectLocator.AllowedSubjects(ctx, ar)
+	if err != nil {
+		// do not forward the error as it should be due
+		// to cache evicted (cluster)roles
+		s.logger.Debug("cache restocking: error caculating allowed subjects", "namespace", ns.GetName(), "error", err)
+	}
 
-		// store in temp cache
-		for _, sub := range ss {
-			lns := s.withVirtualLabelsAndAnnotationsForAccess(ns, sub)
+	// remove duplicates from allowed subjects
+	ss = s.removeDuplicateSubjects(ss)
+
+	// enforce visibility label
+	s.setVisibilityVirtualLabel(ns, ss)
+
+	trace.SpanFromContext(ctx).AddEvent("namespace evaluated", trace.WithAttributes(
+		attribute.String(attributeNamespace, ns.GetName()),
+		attribute.Int(attributeSubjects, len(ss)),
+		attribute.String(attributeVisibility, ns.GetLabels()[VirtualLabelKeyVisibility]),
+	))
+	return ss
+}
+
+// Restock updates the data stored in the cache, increases the cache generation,
+// and notifies the ones waiting on the channel returned by Restocked.
+func (s *SynchronizedAccessCache) Restock(data *AccessData) {
+	var ns NamespaceSubjects
+	if data != nil {
+		ns = newNamespaceSubjects(data)
+	}
 
-			c[sub] = append(c[sub], lns)
+	s.restockMu.Lock()
+	defer s.restockMu.Unlock()
+
+	s.restock(data, ns)
+}
+
+// restockWithStaleness restocks the cache and marks its data as stale or not
+func (s *SynchronizedAccessCache) restockWithStaleness(data *AccessData, subjects NamespaceSubjects, stale bool) {
+	s.restockMu.Lock()
+	defer s.restockMu.Unlock()
+
+	s.stale = stale
+	s.restock(data, subjects)
+}
+
+// restockNamespaces restocks the cache with data patched for the given namespaces.
+// The index of the subjects is updated in place for the given namespaces only,
+// as it is only read holding the restock lock.
+func (s *SynchronizedAccessCache) restockNamespaces(data *AccessData, namespaces map[string]struct{}, subjects NamespaceSubjects) {
+	s.restockMu.Lock()
+	defer s.restockMu.Unlock()
+
+	ns := s.subjects
+	if ns == nil {
+		ns = make(NamespaceSubjects, len(subjects))
+	}
+	for n := range namespaces {
+		delete(ns, n)
+	}
+	maps.Copy(ns, subjects)
+
+	s.restock(data, ns)
+}
+
+// restock updates the cache data and the index of its subjects.
+// It must be called holding the restock lock.
+func (s *SynchronizedAccessCache) restock(data *AccessData, subjects NamespaceSubjects) {
+	s.AccessCache.Restock(data)
+	if data != nil {
+		s.data = data
+		s.subjects = subjects
+	}
+	s.generation++
+
+	close(s.restocked)
+	s.restocked = make(chan struct{})
+}
+
+// Restocked returns a channel that is closed the next time the cache is restocked.
+// To be notified about later restocks, callers need to invoke Restocked again.
+func (s *SynchronizedAccessCache) Restocked() <-chan struct{} {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.restocked
+}
+
+// Stale returns true if the cache is serving data restored from a snapshot
+// and a full synchronization has not completed yet.
+func (s *SynchronizedAccessCache) Stale() bool {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.stale
+}
+
+// LoadSnapshot restocks the cache with the last snapshot persisted in the SnapshotStore.
+// Restored data is marked as stale until a full synchronization completes.
+// It returns false if no SnapshotStore is configured, no snapshot has been persisted,
+// or the snapshot is older than the configured maximum age.
+func (s *SynchronizedAccessCache) LoadSnapshot() (bool, error) {
+	if s.snapshotStore == nil {
+		return false, nil
+	}
+
+	snap, err := s.snapshotStore.Load()
+	if err != nil || snap == nil {
+		return false, err
+	}
+	if age := time.Since(snap.Time); s.snapshotMaxAge > 0 && age > s.snapshotMaxAge {
+		s.logger.Info("ignoring snapshot older than the maximum age", "time", snap.Time, "resourceVersion", snap.ResourceVersion, "maxAge", s.snapshotMaxAge)
+		return false, nil
+	}
+
+	d, err := s.accessDataFromSnapshot(snap)
+	if err != nil {
+		return false, err
+	}
+
+	s.restockMu.Lock()
+	s.restoredAt = snap.Time
+	s.restockMu.Unlock()
+
+	s.restockWithStaleness(d, newNamespaceSubjects(d), true)
+	s.logger.Info("cache restocked from snapshot", "time", snap.Time, "resourceVersion", snap.ResourceVersion)
+	return true, nil
+}
+
+// accessDataFromSnapshot rebuilds the AccessData persisted in the snapshot
+func (s *SynchronizedAccessCache) accessDataFromSnapshot(snap *Snapshot) (*AccessData, error) {
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %q", snap.Version)
+	}
+
+	nn := make(map[string]corev1.Namespace, len(snap.Namespaces))
+	for _, ns := range snap.Namespaces {
+		nn[ns.GetName()] = ns
+	}
+
+	d := &AccessData{}
+	for _, ss := range snap.Subjects {
+		for _, n := range ss.Namespaces {
+			ns, ok := nn[n]
+			if !ok {
+				return nil, fmt.Errorf("invalid snapshot: namespace %q not found", n)
+			}
+			d.Add(ss.Subject, ns)
 		}
 	}
+	return d, nil
+}
 
-	// restock the cache
-	s.Restock(&c)
+// saveSnapshot persists the data in the SnapshotStore, if configured.
+func (s *SynchronizedAccessCache) saveSnapshot(data *AccessData) {
+	if s.snapshotStore == nil {
+		return
+	}
 
-	return c, nil
+	if err := s.snapshotStore.Save(NewSnapshot(data)); err != nil {
+		s.logger.Error("error persisting cache snapshot", "error", err)
+	}
+}
+
+// LastSynchTime returns the time the last successful full synchronization started at.
+// Partial synchronizations are not taken into account, so that failing full
+// synchronizations are not hidden by the partial ones triggered by events.
+// It returns the zero time if the cache has never been synchronized.
+func (s *SynchronizedAccessCache) LastSynchTime() time.Time {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.lastSynch
+}
+
+// DataTime returns the time the data served by the cache has been calculated at:
+// the time the last successful synchronization started at or, if the cache has been
+// restored from a snapshot and not synchronized since, the time the snapshot has been taken at.
+// It returns the zero time if the cache has no data.
+func (s *SynchronizedAccessCache) DataTime() time.Time {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+
+	if s.lastSynch.IsZero() {
+		return s.restoredAt
+	}
+	return s.lastSynch
+}
+
+// markSynchronized records the time of a successful full synchronization
+func (s *SynchronizedAccessCache) markSynchronized(t time.Time) {
+	s.restockMu.Lock()
+	defer s.restockMu.Unlock()
+
+	s.lastSynch = t
+}
+
+// Generation returns the number of times the cache has been restocked.
+func (s *SynchronizedAccessCache) Generation() uint64 {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.generation
+}
+
+// ListWithGeneration lists all the namespaces one or more subjects have access to
+// together with the generation of the cache data they have been retrieved from.
+func (s *SynchronizedAccessCache) ListWithGeneration(subjects ...rbacv1.Subject) ([]corev1.Namespace, uint64) {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.AccessCache.List(subjects...), s.generation
+}
+
+// GetWithGeneration retrieves the namespace with the provided name if any of the subjects has access to it,
+// together with the generation of the cache data it has been retrieved from.
+// It returns nil if none of the subjects has access to the namespace.
+func (s *SynchronizedAccessCache) GetWithGeneration(name string, subjects ...rbacv1.Subject) (*corev1.Namespace, uint64) {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+
+	ns, ok := s.data.Get(name, subjects...)
+	if !ok {
+		return nil, s.generation
+	}
+	return &ns, s.generation
+}
+
+// ListSubjects lists the subjects having access to the namespace.
+// The returned slice is shared with other callers and must not be modified.
+func (s *SynchronizedAccessCache) ListSubjects(namespace string) []rbacv1.Subject {
+	s.restockMu.RLock()
+	defer s.restockMu.RUnlock()
+	return s.subjects[namespace]
 }
 
 func (s *SynchronizedAccessCache) setVisibilityVirtualLabel(ns *corev1.Namespace, subs []rbacv1.Subject) {
@@ -175,50 +532,9 @@ func (s *SynchronizedAccessCache) setVisibilityVirtualLabel(ns *corev1.Namespace
 	ll[VirtualLabelKeyVisibility] = VirtualLabelValueVisibilityPrivate
 }
 
-func (s *SynchronizedAccessCache) withVirtualLabelsAndAnnotationsForAccess(ns corev1.Namespace, sub rbacv1.Subject) corev1.Namespace {
-	// we need to deepcopy otherwise we'll have side effects
-	lns := ns.DeepCopy()
-
-	// calculate data
-	lkind := strings.ToLower(sub.Kind)
-
-	// add labels
-	ll := lns.GetLabels()
-	if ll == nil {
-		ll = map[string]string{}
-	}
-	ll[VirtualLabelKeyAccess] = lkind
-	lns.Labels = ll
-
-	// add annotations
-	aa := lns.GetAnnotations()
-	if aa == nil {
-		aa = map[string]string{}
-	}
-	aa[VirtualAnnotationKeySubjectName] = sub.Name
-	if sub.Namespace != "" {
-		aa[VirtualAnnotationKeySubjectNamespace] = sub.Namespace
-	}
-	lns.Annotations = aa
-
-	// return copy
-	return *lns
-}
-
 func (s *SynchronizedAccessCache) removeDuplicateSubjects(ss []rbacv1.Subject) []rbacv1.Subject {
 	// sort the list of subjects
-	slices.SortFunc(ss, func(a, b rbacv1.Subject) int {
-		if c := strings.Compare(a.APIGroup, b.APIGroup); c != 0 {
-			return c
-		}
-		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
-			return c
-		}
-		if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
-			return c
-		}
-		return strings.Compare(a.Name, b.Name)
-	})
+	slices.SortFunc(ss, compareSubjects)
 
 	// remove duplicates
 	ss = slices.CompactFunc(ss, func(a, b rbacv1.Subject) bool {
@@ -233,9 +549,15 @@ func (s *SynchronizedAccessCache) removeDuplicateSubjects(ss []rbacv1.Subject) [
 }
 
 // Request allows events to request to run a Synch operation.
+// Events on namespaced resources (Namespaces, Roles, and RoleBindings) only request
+// the affected namespace to be synchronized, all the other events request a full synchronization.
 // Only one request is kept in memory. If a Synch operation has already been
-// requested - but still not processed, and a new request comes it will be discarded.
+// requested - but still not processed, and a new request comes it will be merged
+// with the pending one.
 func (s *SynchronizedAccessCache) Request(event Event) bool {
+	// record what needs to be synchronized
+	s.enqueue(event)
+
 	// request to synchronize the cache
 	queued := s.request()
 
@@ -245,6 +567,46 @@ func (s *SynchronizedAccessCache) Request(event Event) bool {
 	return queued
 }
 
+func (s *SynchronizedAccessCache) enqueue(event Event) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	ns, ok := event.affectedNamespace()
+	if !ok {
+		s.pendingFullSynch = true
+		return
+	}
+
+	if s.pendingNamespaces == nil {
+		s.pendingNamespaces = map[string]struct{}{}
+	}
+	s.pendingNamespaces[ns] = struct{}{}
+}
+
+// dequeue returns and resets what has been requested to be synchronized
+func (s *SynchronizedAccessCache) dequeue() (bool, []string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	full, nn := s.pendingFullSynch, slices.Collect(maps.Keys(s.pendingNamespaces))
+	s.pendingFullSynch, s.pendingNamespaces = false, nil
+	return full, nn
+}
+
+// requeue adds back what has been dequeued but not synchronized
+func (s *SynchronizedAccessCache) requeue(full bool, namespaces []string) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	s.pendingFullSynch = s.pendingFullSynch || full
+	if s.pendingNamespaces == nil {
+		s.pendingNamespaces = map[string]struct{}{}
+	}
+	for _, ns := range namespaces {
+		s.pendingNamespaces[ns] = struct{}{}
+	}
+}
+
 func (s *SynchronizedAccessCache) request() bool {
 	select {
 	case s.requested <- struct{}{}:
@@ -256,6 +618,27 @@ func (s *SynchronizedAccessCache) request() bool {
 	}
 }
 
+// synchRequested runs a full synchronization if requested,
+// otherwise it synchronizes the namespaces affected by the events received.
+func (s *SynchronizedAccessCache) synchRequested(ctx context.Context) error {
+	full, nn := s.dequeue()
+
+	var err error
+	switch {
+	case full:
+		err = s.Synch(ctx)
+	case len(nn) > 0:
+		err = s.SynchNamespaces(ctx, nn...)
+	}
+
+	// keep track of what was not synchronized,
+	// so that it is processed with the next request
+	if err != nil {
+		s.requeue(full, nn)
+	}
+	return err
+}
+
 // Start runs two goroutines to keep the cache up-to-date.
 //
 // The former will enqueue requests to synch the cache by intervals of `resyncPeriod`.
@@ -289,7 +672,7 @@ func (s *SynchronizedAccessCache) Start(ctx context.Context) {
 				case <-s.requested:
 					// a new request is present
 					s.logger.Debug("start requested cache synchronization")
-					if err := s.Synch(ctx); isSynchAlreadyRunningErr(err) {
+					if err := s.synchRequested(ctx); isSynchAlreadyRunningErr(err) {
 						s.syncErrorHandler(ctx, err, s)
 					}
 				}
@@ -1,6 +1,10 @@
 package cache
 
-import toolscache "k8s.io/client-go/tools/cache"
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
 
 // EventType the event that's triggering a Request resynch
 type EventType string
@@ -26,6 +30,27 @@ type Event struct {
 // timeTriggeredEvent is the event used to request time-based synchronization
 var timeTriggeredEvent = Event{timeTriggered: true}
 
+// affectedNamespace returns the namespace whose access data may be changed by the event.
+// It returns false if the event may change the access data of any namespace,
+// as it happens for cluster scoped RBAC resources and time-based synchronization.
+func (e Event) affectedNamespace() (string, bool) {
+	obj := e.Object
+	if t, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = t.Obj
+	}
+
+	switch o := obj.(type) {
+	case *corev1.Namespace:
+		return o.GetName(), true
+	case *rbacv1.Role:
+		return o.GetNamespace(), true
+	case *rbacv1.RoleBinding:
+		return o.GetNamespace(), true
+	default:
+		return "", false
+	}
+}
+
 // EventHandlerFuncs returns an EventHandlerFuncs to integrate with Informers.
 func (s *SynchronizedAccessCache) EventHandlerFuncs() toolscache.ResourceEventHandlerFuncs {
 	return toolscache.ResourceEventHandlerFuncs{
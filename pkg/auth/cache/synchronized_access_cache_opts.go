@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"runtime"
 	"time"
 )
 
@@ -15,6 +16,15 @@ type CacheSynchronizerOptions struct {
 	SynchTimeout     time.Duration
 	SyncErrorHandler func(context.Context, error, *SynchronizedAccessCache)
 	Metrics          AccessCacheMetrics
+	// Concurrency is the number of namespaces evaluated in parallel during synchronization
+	Concurrency int
+	// SnapshotStore persists the cache data after each synchronization, if set
+	SnapshotStore SnapshotStore
+	// SnapshotMaxAge is the maximum age of the snapshots the cache is restored from.
+	// Older snapshots are ignored. Zero means no limit.
+	SnapshotMaxAge time.Duration
+	// AccessAttributes is the action subjects need to be allowed to perform in a namespace to access it
+	AccessAttributes AccessAttributes
 }
 
 var defaultCacheSynchronizerOptions = CacheSynchronizerOptions{
@@ -32,7 +42,9 @@ var defaultCacheSynchronizerOptions = CacheSynchronizerOptions{
 
 		s.logger.With("error", err).Log(ctx, level, "error synchronizing cache")
 	},
-	Metrics: &NoOpAccessCacheMetrics{},
+	Metrics:          &NoOpAccessCacheMetrics{},
+	Concurrency:      runtime.GOMAXPROCS(0),
+	AccessAttributes: DefaultAccessAttributes,
 }
 
 // Apply applies the provided options to the SynchronizedAccessCache.
@@ -50,6 +62,16 @@ func (opts *CacheSynchronizerOptions) Apply(s *SynchronizedAccessCache) *Synchro
 		s.syncErrorHandler = defaultCacheSynchronizerOptions.SyncErrorHandler
 	}
 
+	// add concurrency
+	s.concurrency = max(cmp.Or(opts.Concurrency, defaultCacheSynchronizerOptions.Concurrency), 1)
+
+	// add access attributes
+	s.accessAttributes = cmp.Or(opts.AccessAttributes, defaultCacheSynchronizerOptions.AccessAttributes)
+
+	// add snapshot store
+	s.snapshotStore = opts.SnapshotStore
+	s.snapshotMaxAge = opts.SnapshotMaxAge
+
 	// add logger
 	s.logger = cmp.Or(opts.Logger, defaultCacheSynchronizerOptions.Logger)
 
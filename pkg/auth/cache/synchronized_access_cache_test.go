@@ -2,6 +2,9 @@ package cache_test
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -10,8 +13,10 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -176,6 +181,7 @@ var _ = Describe("SynchronizedAccessCache", func() {
 
 		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{
 			SynchTimeout: 50 * time.Millisecond,
+			Concurrency:  1,
 		})
 
 		// when
@@ -283,6 +289,304 @@ var _ = Describe("SynchronizedAccessCache", func() {
 		Expect(nsc.Synch(ctx)).ToNot(HaveOccurred())
 		Expect(nsc.AccessCache.List(groupSubject)).To(ConsistOf(expectedNamespacesGroupAccess))
 	})
+
+	It("notifies restocks", func(ctx context.Context) {
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+		restocked := nsc.Restocked()
+		Expect(restocked).NotTo(BeClosed())
+
+		Expect(nsc.Synch(ctx)).ToNot(HaveOccurred())
+		Expect(restocked).To(BeClosed())
+		Expect(nsc.Restocked()).NotTo(BeClosed())
+	})
+
+	It("increases generation on restock", func(ctx context.Context) {
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+		Expect(nsc.Generation()).To(BeZero())
+
+		Expect(nsc.Synch(ctx)).ToNot(HaveOccurred())
+		Expect(nsc.Generation()).To(BeNumerically("==", 1))
+
+		Expect(nsc.Synch(ctx)).ToNot(HaveOccurred())
+		_, g := nsc.ListWithGeneration(userSubject)
+		Expect(g).To(BeNumerically("==", 2))
+	})
+
+	It("gets an accessible namespace with the generation", func() {
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, mocks.NewMockClientReader(ctrl), cache.CacheSynchronizerOptions{})
+		ns, g := nsc.GetWithGeneration("myns", userSubject)
+		Expect(ns).To(BeNil())
+		Expect(g).To(BeZero())
+
+		nsc.Restock(newAccessData(map[rbacv1.Subject][]corev1.Namespace{userSubject: expectedNamespacesUserAccessPrivate}))
+
+		ns, g = nsc.GetWithGeneration("myns", groupSubject, userSubject)
+		Expect(*ns).To(Equal(expectedNamespacesUserAccessPrivate[0]))
+		Expect(g).To(BeNumerically("==", 1))
+		ns, _ = nsc.GetWithGeneration("myns", groupSubject)
+		Expect(ns).To(BeNil())
+	})
+
+	DescribeTable("evaluates the configured access attributes", func(ctx context.Context, aa cache.AccessAttributes, expected authorizer.AttributesRecord) {
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, nn *corev1.NamespaceList, opts ...client.ListOption) error {
+				(&corev1.NamespaceList{Items: namespaces}).DeepCopyInto(nn)
+				return nil
+			}).
+			Times(1)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), expected).
+			Return([]rbacv1.Subject{userSubject}, nil).
+			Times(1)
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{
+			AccessAttributes: aa,
+		})
+
+		Expect(nsc.Synch(ctx)).To(Succeed())
+		Expect(nsc.AccessCache.List(userSubject)).To(ConsistOf(expectedNamespacesUserAccessPrivate))
+	},
+		Entry("defaults to get namespaces", cache.AccessAttributes{}, authorizer.AttributesRecord{
+			Verb:            "get",
+			Resource:        "namespaces",
+			APIGroup:        "",
+			APIVersion:      "v1",
+			Name:            "myns",
+			Namespace:       "myns",
+			ResourceRequest: true,
+		}),
+		Entry("list pipelineruns", cache.AccessAttributes{Verb: "list", APIGroup: "tekton.dev", Resource: "pipelineruns"}, authorizer.AttributesRecord{
+			Verb:            "list",
+			Resource:        "pipelineruns",
+			APIGroup:        "tekton.dev",
+			Namespace:       "myns",
+			ResourceRequest: true,
+		}),
+	)
+
+	It("records the time of the last successful synch", func(ctx context.Context) {
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(1)
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+		Expect(nsc.LastSynchTime()).To(BeZero())
+
+		st := time.Now()
+		Expect(nsc.Synch(ctx)).ToNot(HaveOccurred())
+		Expect(nsc.LastSynchTime()).To(BeTemporally(">=", st))
+	})
+
+	It("merges concurrently calculated access data in namespaces order", func(ctx context.Context) {
+		nn := make([]corev1.Namespace, 100)
+		for i := range nn {
+			nn[i] = corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("myns-%03d", i)}}
+		}
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, l *corev1.NamespaceList, opts ...client.ListOption) error {
+				(&corev1.NamespaceList{Items: nn}).DeepCopyInto(l)
+				return nil
+			}).
+			Times(1)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, attributes authorizer.Attributes) ([]rbacv1.Subject, error) {
+				// complete out of order
+				time.Sleep(time.Duration(rand.IntN(5)) * time.Millisecond) //nolint:gosec
+				return []rbacv1.Subject{userSubject}, nil
+			}).
+			Times(len(nn))
+
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{
+			Concurrency: 8,
+		})
+
+		Expect(nsc.Synch(ctx)).To(Succeed())
+		names := []string{}
+		for _, ns := range nsc.AccessCache.List(userSubject) {
+			names = append(names, ns.GetName())
+		}
+		Expect(names).To(HaveLen(len(nn)))
+		Expect(slices.IsSorted(names)).To(BeTrue())
+	})
+
+	Context("synchronizing namespaces", func() {
+		var namespaceLister *mocks.MockClientReader
+		var nsc *cache.SynchronizedAccessCache
+
+		otherNamespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "otherns"}}
+
+		BeforeEach(func() {
+			namespaceLister = mocks.NewMockClientReader(ctrl)
+			nsc = cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+		})
+
+		expectFullSynch := func(times int) {
+			namespaceLister.EXPECT().
+				List(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, nn *corev1.NamespaceList, opts ...client.ListOption) error {
+					(&corev1.NamespaceList{Items: append([]corev1.Namespace{otherNamespace}, namespaces...)}).DeepCopyInto(nn)
+					return nil
+				}).
+				Times(times)
+			subjectLocator.EXPECT().
+				AllowedSubjects(gomock.Any(), gomock.Any()).
+				Return([]rbacv1.Subject{userSubject}, nil).
+				Times(2 * times)
+		}
+
+		expectGet := func(ns *corev1.Namespace, err error) {
+			namespaceLister.EXPECT().
+				Get(gomock.Any(), client.ObjectKey{Name: "myns"}, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ client.ObjectKey, obj *corev1.Namespace, opts ...client.GetOption) error {
+					if err != nil {
+						return err
+					}
+					ns.DeepCopyInto(obj)
+					return nil
+				}).
+				Times(1)
+		}
+
+		It("patches the access data of the given namespaces only", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+
+			expectGet(&namespaces[0], nil)
+			subjectLocator.EXPECT().
+				AllowedSubjects(gomock.Any(), gomock.Any()).
+				Return([]rbacv1.Subject{groupSubject}, nil).
+				Times(1)
+
+			Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+			Expect(nsc.AccessCache.List(groupSubject)).To(ConsistOf(expectedNamespacesGroupAccess))
+			Expect(nsc.AccessCache.List(userSubject)).To(ConsistOf(
+				HaveField("ObjectMeta.Name", otherNamespace.Name)))
+			Expect(nsc.Generation()).To(BeNumerically("==", 2))
+		})
+
+		It("does not record partial synchs as the last synch", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+			lastSynch := nsc.LastSynchTime()
+
+			expectGet(&namespaces[0], nil)
+			subjectLocator.EXPECT().
+				AllowedSubjects(gomock.Any(), gomock.Any()).
+				Return([]rbacv1.Subject{groupSubject}, nil).
+				Times(1)
+
+			Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+			Expect(nsc.LastSynchTime()).To(Equal(lastSynch))
+		})
+
+		It("indexes the subjects by namespace", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+			Expect(nsc.ListSubjects("myns")).To(HaveExactElements(userSubject))
+
+			expectGet(&namespaces[0], nil)
+			subjectLocator.EXPECT().
+				AllowedSubjects(gomock.Any(), gomock.Any()).
+				Return([]rbacv1.Subject{userSubject, serviceAccountSubject, groupSubject}, nil).
+				Times(1)
+
+			Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+			Expect(nsc.ListSubjects("myns")).To(HaveExactElements(serviceAccountSubject, groupSubject, userSubject))
+			Expect(nsc.ListSubjects(otherNamespace.Name)).To(HaveExactElements(userSubject))
+			Expect(nsc.ListSubjects("unknown")).To(BeEmpty())
+		})
+
+		It("removes deleted namespaces", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+
+			expectGet(nil, kerrors.NewNotFound(corev1.Resource("namespaces"), "myns"))
+
+			Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+			Expect(nsc.AccessCache.List(userSubject)).To(ConsistOf(
+				HaveField("ObjectMeta.Name", otherNamespace.Name)))
+			Expect(nsc.ListSubjects("myns")).To(BeEmpty())
+			Expect(nsc.ListSubjects(otherNamespace.Name)).To(HaveExactElements(userSubject))
+		})
+
+		It("runs a full synch if the cache has never been synchronized", func(ctx context.Context) {
+			expectFullSynch(1)
+
+			Expect(nsc.SynchNamespaces(ctx, "myns")).To(Succeed())
+			Expect(nsc.AccessCache.List(userSubject)).To(HaveLen(2))
+			Expect(nsc.LastSynchTime()).NotTo(BeZero())
+		})
+
+		It("synchronizes only the namespace affected by a RoleBinding event", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+
+			expectGet(&namespaces[0], nil)
+			subjectLocator.EXPECT().
+				AllowedSubjects(gomock.Any(), gomock.Any()).
+				Return([]rbacv1.Subject{groupSubject}, nil).
+				Times(1)
+
+			restocked := nsc.Restocked()
+			nsc.Start(ctx)
+			nsc.EventHandlerFuncs().OnAdd(&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "mybinding", Namespace: "myns"},
+			}, false)
+
+			Eventually(restocked).Should(BeClosed())
+			Expect(nsc.AccessCache.List(groupSubject)).To(ConsistOf(expectedNamespacesGroupAccess))
+		})
+
+		It("synchronizes the namespace affected by a tombstone", func(ctx context.Context) {
+			expectFullSynch(1)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+
+			expectGet(nil, kerrors.NewNotFound(corev1.Resource("namespaces"), "myns"))
+
+			restocked := nsc.Restocked()
+			nsc.Start(ctx)
+			nsc.EventHandlerFuncs().OnDelete(toolscache.DeletedFinalStateUnknown{
+				Key: "myns",
+				Obj: &namespaces[0],
+			})
+
+			Eventually(restocked).Should(BeClosed())
+			Expect(nsc.AccessCache.List(userSubject)).To(HaveLen(1))
+		})
+
+		It("runs a full synch on ClusterRoleBinding events", func(ctx context.Context) {
+			expectFullSynch(2)
+			Expect(nsc.Synch(ctx)).To(Succeed())
+
+			restocked := nsc.Restocked()
+			nsc.Start(ctx)
+			nsc.EventHandlerFuncs().OnAdd(&rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "mybinding"},
+			}, false)
+
+			Eventually(restocked).Should(BeClosed())
+			Expect(nsc.AccessCache.List(userSubject)).To(HaveLen(2))
+		})
+	})
 })
 
 var _ = DescribeTable("duplicate results", func(ctx context.Context, sr *mocks.MockStaticRoles) {
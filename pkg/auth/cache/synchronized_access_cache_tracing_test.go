@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+	"github.com/konflux-ci/namespace-lister/pkg/auth/cache/mocks"
+)
+
+var _ = Describe("SynchronizedAccessCache tracing", func() {
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tp := otel.GetTracerProvider()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		DeferCleanup(otel.SetTracerProvider, tp)
+	})
+
+	It("traces synchronizations with an event per evaluated namespace", func(ctx context.Context) {
+		// given
+		ctrl := gomock.NewController(GinkgoT())
+		namespaceLister := mocks.NewMockClientReader(ctrl)
+		namespaceLister.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, nn *corev1.NamespaceList, opts ...client.ListOption) error {
+				(&corev1.NamespaceList{Items: []corev1.Namespace{
+					{ObjectMeta: metav1.ObjectMeta{Name: "myns-1"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "myns-2"}},
+				}}).DeepCopyInto(nn)
+				return nil
+			})
+		subjectLocator := mocks.NewMockSubjectLocator(ctrl)
+		subjectLocator.EXPECT().
+			AllowedSubjects(gomock.Any(), gomock.Any()).
+			Return([]rbacv1.Subject{userSubject}, nil).
+			Times(2)
+		nsc := cache.NewSynchronizedAccessCache(subjectLocator, namespaceLister, cache.CacheSynchronizerOptions{})
+
+		// when
+		Expect(nsc.Synch(ctx)).To(Succeed())
+
+		// then
+		ss := recorder.Ended()
+		Expect(ss).To(HaveLen(1))
+		Expect(ss[0].Name()).To(Equal("SynchronizedAccessCache.Synch"))
+		Expect(ss[0].Attributes()).To(ContainElements(
+			attribute.Int("namespacelister.namespaces", 2),
+			attribute.Int("namespacelister.subjects", 1),
+		))
+		Expect(ss[0].Events()).To(ConsistOf(
+			HaveField("Attributes", ContainElement(attribute.String("namespacelister.namespace", "myns-1"))),
+			HaveField("Attributes", ContainElement(attribute.String("namespacelister.namespace", "myns-2"))),
+		))
+	})
+})
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName string = "github.com/konflux-ci/namespace-lister/pkg/auth/cache"
+
+const (
+	attributeNamespace  = "namespacelister.namespace"
+	attributeNamespaces = "namespacelister.namespaces"
+	attributeSubjects   = "namespacelister.subjects"
+	attributeVisibility = "namespacelister.visibility"
+)
+
+// startSpan starts a span with the global TracerProvider
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
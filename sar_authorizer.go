@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	authorizationv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	subjectAccessReviewAllowCacheTTL = 5 * time.Minute
+	subjectAccessReviewDenyCacheTTL  = 30 * time.Second
+)
+
+// NewSubjectAccessReviewAuthorizer builds an authorizer delegating the authorization
+// of the requests to the APIServer via SubjectAccessReviews.
+// Decisions are cached to limit the load on the APIServer.
+//
+// Contrary to the authorizer built with NewAuthorizer, it is not limited to the cached RBAC
+// resources, that only retain the rules granting access to namespaces.
+func NewSubjectAccessReviewAuthorizer(cfg *rest.Config) (authorizer.Authorizer, error) {
+	c, err := authorizationv1.NewForConfig(rest.CopyConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	return authorizerfactory.DelegatingAuthorizerConfig{
+		SubjectAccessReviewClient: c,
+		AllowCacheTTL:             subjectAccessReviewAllowCacheTTL,
+		DenyCacheTTL:              subjectAccessReviewDenyCacheTTL,
+		WebhookRetryBackoff:       &wait.Backoff{Duration: 2 * time.Second, Cap: 2 * time.Minute, Steps: 100, Factor: 2, Jitter: 2},
+	}.New()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName string = "github.com/konflux-ci/namespace-lister"
+
+const (
+	attributeAuthenticatorName = "namespacelister.authenticator.name"
+	attributeAuthenticatorType = "namespacelister.authenticator.type"
+	attributeAuthenticated     = "namespacelister.authenticated"
+	attributeNamespaces        = "namespacelister.namespaces"
+	attributeContentType       = "namespacelister.content_type"
+)
+
+// startSpan starts a span with the global TracerProvider
+func startSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+package main_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+
+	namespacelister "github.com/konflux-ci/namespace-lister"
+	"github.com/konflux-ci/namespace-lister/mocks"
+)
+
+var _ = Describe("Tracing", func() {
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+
+	var recorder *tracetest.SpanRecorder
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tp, p := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		DeferCleanup(func() {
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(p)
+		})
+	})
+
+	It("traces requests continuing the proxy's trace", func(ctx context.Context) {
+		// given
+		ar, err := namespacelister.NewAuthenticator(ctx, namespacelister.AuthenticatorOptions{
+			Client:         mocks.NewMockFakeInterface(gomock.NewController(GinkgoT())),
+			UsernameHeader: "X-User",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		lister := NamespaceListerMock{
+			ListNamespacesFunc: func(ctx context.Context, username string, groups []string) (*corev1.NamespaceList, error) {
+				return &corev1.NamespaceList{}, nil
+			},
+		}
+		handler := namespacelister.NewAPIServer(slog.Default(), ar, lister, nil).Handler
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/namespaces", nil)
+		Expect(err).NotTo(HaveOccurred())
+		r.Header.Set("X-User", "myuser")
+		r.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+
+		// when
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		// then
+		Expect(w.Result().StatusCode).To(Equal(http.StatusOK))
+		ss := recorder.Ended()
+		names := make([]string, 0, len(ss))
+		for _, s := range ss {
+			Expect(s.SpanContext().TraceID().String()).To(Equal(traceID))
+			names = append(names, s.Name())
+		}
+		Expect(names).To(ConsistOf(
+			"GET /api/v1/namespaces",
+			"Authenticate",
+			"Authenticate Header",
+			"ListNamespaces",
+			"Serialize",
+		))
+		for _, s := range ss {
+			if s.Name() == "GET /api/v1/namespaces" {
+				Expect(s.Parent().SpanID().String()).To(Equal(parentSpanID))
+			}
+		}
+	})
+})